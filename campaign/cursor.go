@@ -0,0 +1,70 @@
+package campaign
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("Invalid pagination cursor!")
+
+// goalReachedValue is the closest-to-goal sort key of campaigns that already
+// reached their goal, which puts them after every campaign still short of it.
+const goalReachedValue = math.MaxInt64
+
+// noDeadlineValue is the ending soon sort key of campaigns without an end
+// date, which puts them after every campaign that has one.
+const noDeadlineValue = math.MaxInt64
+
+// sortValue returns the key a campaign is sorted on, before its ID, in the
+// given sort order. It must agree with the ordering applyPage builds.
+func sortValue(sort string, campaign Campaign) int64 {
+	switch sort {
+	case SortMostFunded:
+		return int64(campaign.CurrentAmount)
+	case SortClosestToGoal:
+		if campaign.CurrentAmount >= campaign.GoalAmount {
+			return goalReachedValue
+		}
+		return int64(campaign.GoalAmount - campaign.CurrentAmount)
+	case SortEndingSoon:
+		if campaign.EndsAt == nil {
+			return noDeadlineValue
+		}
+		return campaign.EndsAt.UnixNano()
+	case SortMostBackers:
+		return int64(campaign.BackerCount)
+	default:
+		return int64(campaign.ID)
+	}
+}
+
+// cursorTime converts the sort key of the ending soon order back to the
+// end date it was taken from.
+func cursorTime(cursor Cursor) time.Time {
+	return time.Unix(0, cursor.Value)
+}
+
+// encodeCursor returns the opaque cursor pointing at a campaign. The sort
+// order is part of the cursor so it cannot be reused with another order.
+func encodeCursor(sort string, campaign Campaign) string {
+	raw := fmt.Sprintf("%s:%d:%d", sort, sortValue(sort, campaign), campaign.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(sort string, encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	_, err = fmt.Sscanf(string(raw), sort+":%d:%d", &cursor.Value, &cursor.ID)
+	if err != nil || cursor.ID <= 0 || fmt.Sprintf("%s:%d:%d", sort, cursor.Value, cursor.ID) != string(raw) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
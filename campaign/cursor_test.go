@@ -0,0 +1,120 @@
+package campaign
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestEndingSoonCursorWalk(t *testing.T) {
+	day := func(n int) *time.Time {
+		endsAt := time.Date(2026, 11, n, 12, 0, 0, 0, time.UTC)
+		return &endsAt
+	}
+
+	campaigns := []Campaign{
+		{ID: 1, EndsAt: nil},
+		{ID: 2, EndsAt: day(5)},
+		{ID: 3, EndsAt: nil},
+		{ID: 4, EndsAt: day(3)},
+		{ID: 5, EndsAt: day(5)},
+		{ID: 6, EndsAt: nil},
+		{ID: 7, EndsAt: day(1)},
+	}
+
+	// The order applyPage builds: by end date, campaigns without one last,
+	// then by ID.
+	want := []int{7, 4, 2, 5, 1, 3, 6}
+
+	// Every page holds the campaigns after the cursor in the order above,
+	// compared the way applyPage compares them.
+	for _, limit := range []int{1, 2, 3} {
+		got := []int{}
+		var cursor *Cursor
+
+		for len(got) <= len(campaigns) {
+			page := []Campaign{}
+			for _, id := range want {
+				campaign := campaigns[slices.IndexFunc(campaigns, func(c Campaign) bool { return c.ID == id })]
+				if cursor == nil || afterCursor(campaign, *cursor) {
+					page = append(page, campaign)
+				}
+			}
+
+			if len(page) == 0 {
+				break
+			}
+			page = page[:min(limit, len(page))]
+
+			for _, campaign := range page {
+				got = append(got, campaign.ID)
+			}
+
+			var err error
+			cursor, err = decodeCursor(SortEndingSoon, encodeCursor(SortEndingSoon, page[len(page)-1]))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("pages of %d = %v, want %v", limit, got, want)
+		}
+	}
+}
+
+// afterCursor mirrors the ending soon cursor condition of applyPage.
+func afterCursor(campaign Campaign, cursor Cursor) bool {
+	if cursor.Value == noDeadlineValue {
+		return campaign.EndsAt == nil && campaign.ID > cursor.ID
+	}
+
+	endsAt := cursorTime(cursor)
+	if campaign.EndsAt == nil {
+		return true
+	}
+
+	return campaign.EndsAt.After(endsAt) || (campaign.EndsAt.Equal(endsAt) && campaign.ID > cursor.ID)
+}
+
+func TestEndingSoonPageQuery(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user@tcp(localhost:3306)/test", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endsAt := time.Date(2026, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor *Cursor
+		want   string
+	}{
+		{"first page", nil, "ORDER BY ends_at IS NULL ASC,ends_at ASC,id ASC"},
+		{"after a deadline", &Cursor{ID: 2, Value: endsAt.UnixNano()}, "AND (ends_at > ? OR ends_at IS NULL OR (ends_at = ? AND id > ?))"},
+		{"after no deadline", &Cursor{ID: 3, Value: noDeadlineValue}, "AND (ends_at IS NULL AND id > ?)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var campaigns []Campaign
+			statement := applyPage(applyFilter(db.Model(&Campaign{}), CampaignFilter{}), PageQuery{Sort: SortEndingSoon, Cursor: test.cursor, Limit: 2}).Find(&campaigns).Statement
+
+			sql := statement.SQL.String()
+			if !strings.Contains(sql, test.want) {
+				t.Errorf("query = %s, want it to contain %s", sql, test.want)
+			}
+
+			if test.cursor != nil && test.cursor.Value != noDeadlineValue && !slices.ContainsFunc(statement.Vars, func(v interface{}) bool {
+				value, ok := v.(time.Time)
+				return ok && value.Equal(endsAt)
+			}) {
+				t.Errorf("query vars = %v, want the cursor end date %v", statement.Vars, endsAt)
+			}
+		})
+	}
+}
@@ -8,20 +8,80 @@ import (
 type Campaign struct {
 	ID               int
 	UserID           int
+	CategoryID       int
 	Name             string
 	ShortDescription string
 	Description      string
-	Perks            string
 	BackerCount      int
 	GoalAmount       int
 	CurrentAmount    int
 	Slug             string
+	Status           string
+	FundingModel     string
+	StartsAt         *time.Time
+	EndsAt           *time.Time
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	CampaignImages   []CampaignImage
+	RewardTiers      []RewardTier
+	Updates          []CampaignUpdate
+	Tags             []CampaignTag
+	Category         Category
 	User             user.User
 }
 
+const (
+	SortNewest        = "newest"
+	SortMostFunded    = "most_funded"
+	SortClosestToGoal = "closest_to_goal"
+	SortEndingSoon    = "ending_soon"
+	SortMostBackers   = "most_backers"
+)
+
+// CampaignFilter narrows down a campaign listing. Zero values match every
+// public campaign; a campaign must carry all of Tags to match. The funded
+// percentages are the current amount relative to the goal amount.
+// Unpublished also matches drafts and campaigns under review, for listing a
+// creator's own campaigns.
+type CampaignFilter struct {
+	UserID           int
+	Unpublished      bool
+	Status           string
+	CategoryIDs      []int
+	Tags             []string
+	MinGoal          int
+	MaxGoal          int
+	MinFundedPercent int
+	MaxFundedPercent int
+}
+
+// PageQuery selects one page of a sorted list, either right after the
+// campaign the cursor points at or Offset campaigns from the top. A zero
+// Limit returns everything.
+type PageQuery struct {
+	Sort   string
+	Cursor *Cursor
+	Offset int
+	Limit  int
+}
+
+// Cursor is the position of the last campaign of a page in its sort order.
+// Value holds the sort key of that campaign; see sortValue.
+type Cursor struct {
+	ID    int
+	Value int64
+}
+
+// Page describes the page that was returned for a PageQuery. Number is only
+// set for pages requested by number.
+type Page struct {
+	Limit      int
+	Number     int
+	NextCursor string
+	HasMore    bool
+	Total      int
+}
+
 type CampaignImage struct {
 	ID         int
 	CampaignID int
@@ -30,3 +90,126 @@ type CampaignImage struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+// RewardTier is a reward backers can select when donating at least
+// MinimumPledge. A LimitedQuantity of zero means the tier is unlimited;
+// Reserved counts the donations currently holding one of its rewards.
+type RewardTier struct {
+	ID                int
+	CampaignID        int
+	Title             string
+	Description       string
+	MinimumPledge     int
+	LimitedQuantity   int
+	Reserved          int
+	EstimatedDelivery *time.Time
+	ShippingRequired  bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Remaining returns how many rewards of a limited tier are left, or -1 for an
+// unlimited tier.
+func (t RewardTier) Remaining() int {
+	if t.LimitedQuantity == 0 {
+		return -1
+	}
+
+	return max(t.LimitedQuantity-t.Reserved, 0)
+}
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityBackers = "backers"
+)
+
+// CampaignUpdate is a progress update the owner posts on a campaign. The
+// body is Markdown. Updates visible to backers only are listed to everyone
+// else with their title alone.
+type CampaignUpdate struct {
+	ID         int
+	CampaignID int
+	UserID     int
+	Title      string
+	Body       string
+	Visibility string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Images     []CampaignUpdateImage
+}
+
+type CampaignUpdateImage struct {
+	ID               int
+	CampaignUpdateID int
+	FileName         string
+	CreatedAt        time.Time
+}
+
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
+// CampaignUpdateNotification is a queued email telling one backer about a new
+// update. Emails that could not be sent are tried again at NextAttemptAt.
+type CampaignUpdateNotification struct {
+	ID               int
+	CampaignID       int
+	CampaignUpdateID int
+	Email            string
+	Status           string
+	Attempts         int
+	LastError        string
+	NextAttemptAt    *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Campaign         Campaign
+	CampaignUpdate   CampaignUpdate
+}
+
+// BackerDirectory knows who backed a campaign and how to reach them. The
+// backers are found in the transaction data, which this package cannot
+// depend on, so it is implemented by the transaction package.
+type BackerDirectory interface {
+	IsBacker(campaignID int, userID int) (bool, error)
+	GetBackerEmails(campaignID int) ([]string, error)
+	NotifyBacker(email string, campaign Campaign, update CampaignUpdate) error
+}
+
+// CampaignSlugHistory records a slug a campaign used before it was renamed,
+// so links with the old slug keep resolving to the campaign.
+type CampaignSlugHistory struct {
+	ID         int
+	CampaignID int
+	Slug       string
+	CreatedAt  time.Time
+}
+
+// CampaignTag is a free-form tag the owner put on a campaign. Names are
+// stored in lowercase.
+type CampaignTag struct {
+	ID         int
+	CampaignID int
+	Name       string
+	CreatedAt  time.Time
+}
+
+// Category groups campaigns. Categories are managed by admins and form a
+// hierarchy; a ParentID of zero makes a top-level category.
+type Category struct {
+	ID        int
+	ParentID  int
+	Name      string
+	Slug      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CategoryNode is a category with its subcategories and the number of live
+// campaigns in it, its subcategories included.
+type CategoryNode struct {
+	Category          Category
+	LiveCampaignCount int
+	Children          []CategoryNode
+}
@@ -1,16 +1,28 @@
 package campaign
 
-import "strings"
+import "time"
 
 type CampaignFormatter struct {
-	ID               int    `json:"id"`
-	UserID           int    `json:"user_id"`
-	Name             string `json:"name"`
-	ShortDescription string `json:"short_description"`
-	ImageURL         string `json:"image_url"`
-	GoalAmount       int    `json:"goal_amount"`
-	CurrentAmount    int    `json:"current_amount"`
-	Slug             string `json:"slug"`
+	ID               int                        `json:"id"`
+	UserID           int                        `json:"user_id"`
+	Name             string                     `json:"name"`
+	ShortDescription string                     `json:"short_description"`
+	ImageURL         string                     `json:"image_url"`
+	GoalAmount       int                        `json:"goal_amount"`
+	CurrentAmount    int                        `json:"current_amount"`
+	Slug             string                     `json:"slug"`
+	Status           string                     `json:"status"`
+	FundingModel     string                     `json:"funding_model"`
+	StartsAt         *time.Time                 `json:"starts_at"`
+	EndsAt           *time.Time                 `json:"ends_at"`
+	Category         *CampaignCategoryFormatter `json:"category"`
+	Tags             []string                   `json:"tags"`
+}
+
+type CampaignCategoryFormatter struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
 }
 
 func FormatCampaign(campaign Campaign) CampaignFormatter {
@@ -23,6 +35,12 @@ func FormatCampaign(campaign Campaign) CampaignFormatter {
 		GoalAmount:       campaign.GoalAmount,
 		CurrentAmount:    campaign.CurrentAmount,
 		Slug:             campaign.Slug,
+		Status:           campaign.Status,
+		FundingModel:     campaign.FundingModel,
+		StartsAt:         campaign.StartsAt,
+		EndsAt:           campaign.EndsAt,
+		Category:         formatCampaignCategory(campaign.Category),
+		Tags:             formatTags(campaign.Tags),
 	}
 
 	if len(campaign.CampaignImages) > 0 {
@@ -53,7 +71,14 @@ type CampaignDetailFormatter struct {
 	CurrentAmount    int                            `json:"current_amount"`
 	Description      string                         `json:"description"`
 	Slug             string                         `json:"slug"`
-	Perks            []string                       `json:"perks"`
+	Status           string                         `json:"status"`
+	FundingModel     string                         `json:"funding_model"`
+	StartsAt         *time.Time                     `json:"starts_at"`
+	EndsAt           *time.Time                     `json:"ends_at"`
+	Category         *CampaignCategoryFormatter     `json:"category"`
+	Tags             []string                       `json:"tags"`
+	RewardTiers      []RewardTierFormatter          `json:"reward_tiers"`
+	Updates          []CampaignUpdateFormatter      `json:"updates"`
 	User             CampaignDetailUserFormatter    `json:"user"`
 	Images           []CampaignDetailImageFormatter `json:"images"`
 }
@@ -79,20 +104,21 @@ func FormatCampaignDetail(campaign Campaign) CampaignDetailFormatter {
 		CurrentAmount:    campaign.CurrentAmount,
 		Description:      campaign.Description,
 		Slug:             campaign.Slug,
+		Status:           campaign.Status,
+		FundingModel:     campaign.FundingModel,
+		StartsAt:         campaign.StartsAt,
+		EndsAt:           campaign.EndsAt,
+		Category:         formatCampaignCategory(campaign.Category),
+		Tags:             formatTags(campaign.Tags),
 	}
 
 	if len(campaign.CampaignImages) > 0 {
 		formatter.ImageURL = campaign.CampaignImages[0].FileName
 	}
 
-	var perks []string
-
-	for _, perk := range strings.Split(campaign.Perks, ",") {
-		perks = append(perks, strings.TrimSpace(perk))
-	}
-
 	//Set Objek data
-	formatter.Perks = perks
+	formatter.RewardTiers = FormatRewardTiers(campaign.RewardTiers)
+	formatter.Updates = FormatCampaignUpdates(campaign.Updates, false)
 
 	user := campaign.User
 	campaignDetailUserFormatter := CampaignDetailUserFormatter{}
@@ -121,3 +147,181 @@ func FormatCampaignDetail(campaign Campaign) CampaignDetailFormatter {
 
 	return formatter
 }
+
+type RewardTierFormatter struct {
+	ID                int    `json:"id"`
+	Title             string `json:"title"`
+	Description       string `json:"description"`
+	MinimumPledge     int    `json:"minimum_pledge"`
+	LimitedQuantity   int    `json:"limited_quantity"`
+	Remaining         *int   `json:"remaining"`
+	EstimatedDelivery string `json:"estimated_delivery"`
+	ShippingRequired  bool   `json:"shipping_required"`
+	BackerCount       int    `json:"backer_count"`
+}
+
+// FormatRewardTier formats a reward tier. Remaining is null for unlimited
+// tiers and the estimated delivery is a month such as "2025-06".
+func FormatRewardTier(rewardTier RewardTier) RewardTierFormatter {
+	formatter := RewardTierFormatter{
+		ID:               rewardTier.ID,
+		Title:            rewardTier.Title,
+		Description:      rewardTier.Description,
+		MinimumPledge:    rewardTier.MinimumPledge,
+		LimitedQuantity:  rewardTier.LimitedQuantity,
+		ShippingRequired: rewardTier.ShippingRequired,
+		BackerCount:      rewardTier.Reserved,
+	}
+
+	if remaining := rewardTier.Remaining(); remaining >= 0 {
+		formatter.Remaining = &remaining
+	}
+
+	if rewardTier.EstimatedDelivery != nil {
+		formatter.EstimatedDelivery = rewardTier.EstimatedDelivery.Format("2006-01")
+	}
+
+	return formatter
+}
+
+func FormatRewardTiers(rewardTiers []RewardTier) []RewardTierFormatter {
+	rewardTiersFormatter := []RewardTierFormatter{}
+
+	for _, rewardTier := range rewardTiers {
+		rewardTiersFormatter = append(rewardTiersFormatter, FormatRewardTier(rewardTier))
+	}
+
+	return rewardTiersFormatter
+}
+
+// formatCampaignCategory returns nil for uncategorized campaigns.
+func formatCampaignCategory(category Category) *CampaignCategoryFormatter {
+	if category.ID == 0 {
+		return nil
+	}
+
+	return &CampaignCategoryFormatter{
+		ID:   category.ID,
+		Name: category.Name,
+		Slug: category.Slug,
+	}
+}
+
+func formatTags(tags []CampaignTag) []string {
+	names := []string{}
+
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+type CategoryFormatter struct {
+	ID                int                 `json:"id"`
+	ParentID          int                 `json:"parent_id"`
+	Name              string              `json:"name"`
+	Slug              string              `json:"slug"`
+	LiveCampaignCount int                 `json:"live_campaign_count"`
+	Children          []CategoryFormatter `json:"children"`
+}
+
+func FormatCategoryNode(node CategoryNode) CategoryFormatter {
+	formatter := FormatCategory(node.Category)
+	formatter.LiveCampaignCount = node.LiveCampaignCount
+	formatter.Children = FormatCategoryNodes(node.Children)
+
+	return formatter
+}
+
+func FormatCategoryNodes(nodes []CategoryNode) []CategoryFormatter {
+	categoriesFormatter := []CategoryFormatter{}
+
+	for _, node := range nodes {
+		categoriesFormatter = append(categoriesFormatter, FormatCategoryNode(node))
+	}
+
+	return categoriesFormatter
+}
+
+func FormatCategory(category Category) CategoryFormatter {
+	return CategoryFormatter{
+		ID:       category.ID,
+		ParentID: category.ParentID,
+		Name:     category.Name,
+		Slug:     category.Slug,
+		Children: []CategoryFormatter{},
+	}
+}
+
+type SearchResultFormatter struct {
+	CampaignFormatter
+	Score           float64 `json:"score"`
+	HighlightedName string  `json:"highlighted_name"`
+	Snippet         string  `json:"snippet"`
+}
+
+// FormatSearchResults formats search results. The highlighted name and
+// snippet are HTML with the matched words wrapped in <mark> tags.
+func FormatSearchResults(results []SearchResult) []SearchResultFormatter {
+	resultsFormatter := []SearchResultFormatter{}
+
+	for _, result := range results {
+		resultsFormatter = append(resultsFormatter, SearchResultFormatter{
+			CampaignFormatter: FormatCampaign(result.Campaign),
+			Score:             result.Hit.Score,
+			HighlightedName:   result.Hit.HighlightedName,
+			Snippet:           result.Hit.Snippet,
+		})
+	}
+
+	return resultsFormatter
+}
+
+type CampaignUpdateFormatter struct {
+	ID         int       `json:"id"`
+	CampaignID int       `json:"campaign_id"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	Visibility string    `json:"visibility"`
+	Locked     bool      `json:"locked"`
+	Images     []string  `json:"images"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// FormatCampaignUpdate formats an update. Unless canViewBackersOnly is set,
+// updates for backers only are locked: their body and images are left out.
+func FormatCampaignUpdate(update CampaignUpdate, canViewBackersOnly bool) CampaignUpdateFormatter {
+	formatter := CampaignUpdateFormatter{
+		ID:         update.ID,
+		CampaignID: update.CampaignID,
+		Title:      update.Title,
+		Visibility: update.Visibility,
+		Images:     []string{},
+		CreatedAt:  update.CreatedAt,
+		UpdatedAt:  update.UpdatedAt,
+	}
+
+	if update.Visibility == VisibilityBackers && !canViewBackersOnly {
+		formatter.Locked = true
+		return formatter
+	}
+
+	formatter.Body = update.Body
+	for _, image := range update.Images {
+		formatter.Images = append(formatter.Images, image.FileName)
+	}
+
+	return formatter
+}
+
+func FormatCampaignUpdates(updates []CampaignUpdate, canViewBackersOnly bool) []CampaignUpdateFormatter {
+	updatesFormatter := []CampaignUpdateFormatter{}
+
+	for _, update := range updates {
+		updatesFormatter = append(updatesFormatter, FormatCampaignUpdate(update, canViewBackersOnly))
+	}
+
+	return updatesFormatter
+}
@@ -1,17 +1,48 @@
 package campaign
 
-import "cfa-backend/user"
+import (
+	"cfa-backend/user"
+	"time"
+)
+
+type GetCampaignsInput struct {
+	UserID    int      `form:"user_id"`
+	Category  string   `form:"category"`
+	Tags      []string `form:"tags"`
+	Status    string   `form:"status" binding:"omitempty,oneof=draft pending_review live successful failed closed"`
+	MinGoal   int      `form:"min_goal" binding:"omitempty,min=0"`
+	MaxGoal   int      `form:"max_goal" binding:"omitempty,min=0"`
+	MinFunded int      `form:"min_funded" binding:"omitempty,min=0"`
+	MaxFunded int      `form:"max_funded" binding:"omitempty,min=0"`
+	Sort      string   `form:"sort" binding:"omitempty,oneof=newest most_funded closest_to_goal ending_soon most_backers"`
+	Cursor    string   `form:"cursor"`
+	Page      int      `form:"page" binding:"omitempty,min=1"`
+	Limit     int      `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type GetCampaignBySlugInput struct {
+	Slug string `uri:"slug" binding:"required"`
+}
+
+type SearchCampaignsInput struct {
+	Query string `form:"q" binding:"required,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
 
 type GetCampaignDetailInput struct {
 	ID int `uri:"id" binding:"required"`
 }
 
 type CreateCampaignInput struct {
-	Name             string `json:"name" binding:"required"`
-	ShortDescription string `json:"short_description" binding:"required"`
-	Description      string `json:"description" binding:"required"`
-	GoalAmount       int    `json:"goal_amount" binding:"required"`
-	Perks            string `json:"perks" binding:"required"`
+	Name             string     `json:"name" binding:"required"`
+	ShortDescription string     `json:"short_description" binding:"required"`
+	Description      string     `json:"description" binding:"required"`
+	GoalAmount       int        `json:"goal_amount" binding:"required"`
+	FundingModel     string     `json:"funding_model" binding:"omitempty,oneof=all_or_nothing keep_what_you_raise"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+	CategoryID       int        `json:"category_id"`
+	Tags             []string   `json:"tags" binding:"max=10,dive,max=30"`
 	User             user.User
 }
 
@@ -20,3 +51,40 @@ type CreateCampaignImageInput struct {
 	IsPrimary  bool `form:"is_primary"`
 	User       user.User
 }
+
+type GetRewardTierInput struct {
+	ID     int `uri:"id" binding:"required"`
+	TierID int `uri:"tier_id" binding:"required"`
+}
+
+type RewardTierInput struct {
+	Title             string `json:"title" binding:"required,max=100"`
+	Description       string `json:"description" binding:"max=1000"`
+	MinimumPledge     int    `json:"minimum_pledge" binding:"required,min=1"`
+	LimitedQuantity   int    `json:"limited_quantity" binding:"min=0"`
+	EstimatedDelivery string `json:"estimated_delivery" binding:"omitempty,datetime=2006-01"`
+	ShippingRequired  bool   `json:"shipping_required"`
+	User              user.User
+}
+
+type GetCategoryInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type CategoryInput struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	ParentID int    `json:"parent_id"`
+	User     user.User
+}
+
+type GetCampaignUpdateInput struct {
+	ID       int `uri:"id" binding:"required"`
+	UpdateID int `uri:"update_id" binding:"required"`
+}
+
+type CampaignUpdateInput struct {
+	Title      string `json:"title" binding:"required,max=150"`
+	Body       string `json:"body" binding:"required"`
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public backers"`
+	User       user.User
+}
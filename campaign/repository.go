@@ -1,17 +1,64 @@
 package campaign
 
-import "gorm.io/gorm"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
-	FindAll() ([]Campaign, error)
-	FindByUserID(userID int) ([]Campaign, error)
+	FindAll(filter CampaignFilter, page PageQuery) ([]Campaign, error)
+	CountAll(filter CampaignFilter) (int, error)
+	FindByIDs(IDs []int) ([]Campaign, error)
 	FindByID(ID int) (Campaign, error)
+	FindBySlug(slug string) (Campaign, error)
+	FindSlugHistory(slug string) (CampaignSlugHistory, error)
+	FindSlugOwner(slug string) (int, error)
+	FindCampaignUpdatesByCampaignID(campaignID int) ([]CampaignUpdate, error)
+	FindCampaignUpdateByID(ID int) (CampaignUpdate, error)
+	SaveCampaignUpdate(update CampaignUpdate, recipients []string) (CampaignUpdate, error)
+	UpdateCampaignUpdate(update CampaignUpdate) (CampaignUpdate, error)
+	DeleteCampaignUpdate(update CampaignUpdate) error
+	CreateCampaignUpdateImage(image CampaignUpdateImage) (CampaignUpdateImage, error)
+	FindNotificationsDue(now time.Time, limit int) ([]CampaignUpdateNotification, error)
+	MarkNotificationSent(notification CampaignUpdateNotification) error
+	DeferNotification(notification CampaignUpdateNotification, nextAttempt time.Time) error
+	FailNotification(notification CampaignUpdateNotification) error
 	Save(campaign Campaign) (Campaign, error)
 	Update(campaign Campaign) (Campaign, error)
+	SetStartsAt(campaign Campaign, startsAt time.Time) error
 	CreateImage(campaignImage CampaignImage) (CampaignImage, error)
 	MarkAllImagesAsNonPrimary(campaignID int) (bool, error)
+	FindRewardTiersByCampaignID(campaignID int) ([]RewardTier, error)
+	FindRewardTierByID(ID int) (RewardTier, error)
+	SaveRewardTier(rewardTier RewardTier) (RewardTier, error)
+	UpdateRewardTier(rewardTier RewardTier) (RewardTier, error)
+	DeleteRewardTier(rewardTier RewardTier) error
+	ReserveRewardTier(ID int) error
+	ReleaseRewardTier(ID int) error
+	FindByStatus(status string) ([]Campaign, error)
+	FindLiveEndedBefore(before time.Time, limit int) ([]Campaign, error)
+	FindFailedByFundingModel(fundingModel string) ([]Campaign, error)
+	UpdateStatus(campaign Campaign, toStatus string) (bool, error)
+	FindCategories() ([]Category, error)
+	FindCategoryByID(ID int) (Category, error)
+	FindCategoryBySlug(slug string) (Category, error)
+	SaveCategory(category Category) (Category, error)
+	UpdateCategory(category Category) (Category, error)
+	DeleteCategory(category Category) error
+	CountLiveByCategory() (map[int]int, error)
 }
 
+var (
+	ErrRewardTierSoldOut = errors.New("The selected reward tier is sold out!")
+	ErrCategoryInUse     = errors.New("Category still has subcategories or campaigns!")
+	ErrCampaignChanged   = errors.New("The campaign has changed in the meantime, please try again!")
+	ErrDuplicateSlug     = errors.New("Campaign slug is already in use!")
+)
+
 type repository struct {
 	db *gorm.DB
 }
@@ -22,9 +69,18 @@ func NewRepository(db *gorm.DB) *repository {
 
 var ISPRIMARY int = 1
 
-func (r *repository) FindAll() ([]Campaign, error) {
+// notificationInsertBatchSize is how many update emails are queued per
+// insert statement.
+const notificationInsertBatchSize = 500
+
+// FindAll lists the campaigns matching filter, only the publicly visible ones
+// unless filter.Unpublished is set. When the page has a limit, one campaign
+// more than the limit is loaded so the caller can tell whether another page
+// follows.
+func (r *repository) FindAll(filter CampaignFilter, page PageQuery) ([]Campaign, error) {
 	var campaigns []Campaign
-	err := r.db.Preload("CampaignImages", "campaign_images.is_primary = ?", ISPRIMARY).Find(&campaigns).Error
+	query := r.db.Preload("CampaignImages", "campaign_images.is_primary = ?", ISPRIMARY).Preload("Category").Preload("Tags")
+	err := applyPage(applyFilter(query, filter), page).Find(&campaigns).Error
 
 	if err != nil {
 		return campaigns, err
@@ -33,9 +89,27 @@ func (r *repository) FindAll() ([]Campaign, error) {
 	return campaigns, nil
 }
 
-func (r *repository) FindByUserID(userID int) ([]Campaign, error) {
+// CountAll counts the campaigns FindAll lists for filter.
+func (r *repository) CountAll(filter CampaignFilter) (int, error) {
+	var count int64
+	err := applyFilter(r.db.Model(&Campaign{}), filter).Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// FindByIDs returns the publicly visible campaigns among IDs, in no
+// particular order.
+func (r *repository) FindByIDs(IDs []int) ([]Campaign, error) {
 	var campaigns []Campaign
-	err := r.db.Where("user_id = ?", userID).Preload("CampaignImages", "campaign_images.is_primary = ?", ISPRIMARY).Find(&campaigns).Error
+	if len(IDs) == 0 {
+		return campaigns, nil
+	}
+
+	err := r.db.Where("id IN ? AND status IN ?", IDs, publicStatuses).Preload("CampaignImages", "campaign_images.is_primary = ?", ISPRIMARY).Preload("Category").Preload("Tags").Find(&campaigns).Error
 
 	if err != nil {
 		return campaigns, err
@@ -46,7 +120,9 @@ func (r *repository) FindByUserID(userID int) ([]Campaign, error) {
 
 func (r *repository) FindByID(ID int) (Campaign, error) {
 	var campaign Campaign
-	err := r.db.Preload("User").Preload("CampaignImages").Where("id = ?", ID).Find(&campaign).Error
+	err := r.db.Preload("User").Preload("CampaignImages").Preload("Category").Preload("Tags").Preload("RewardTiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("minimum_pledge ASC, id ASC")
+	}).Where("id = ?", ID).Find(&campaign).Error
 
 	if err != nil {
 		return campaign, err
@@ -55,9 +131,58 @@ func (r *repository) FindByID(ID int) (Campaign, error) {
 	return campaign, nil
 }
 
+func (r *repository) FindBySlug(slug string) (Campaign, error) {
+	var campaign Campaign
+	err := r.db.Where("slug = ?", slug).Find(&campaign).Error
+
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		return campaign, nil
+	}
+
+	return r.FindByID(campaign.ID)
+}
+
+func (r *repository) FindSlugHistory(slug string) (CampaignSlugHistory, error) {
+	var history CampaignSlugHistory
+	err := r.db.Where("slug = ?", slug).Find(&history).Error
+
+	if err != nil {
+		return history, err
+	}
+
+	return history, nil
+}
+
+// FindSlugOwner returns the ID of the campaign using slug now or in the past,
+// or zero when the slug is free.
+func (r *repository) FindSlugOwner(slug string) (int, error) {
+	var campaign Campaign
+	err := r.db.Select("id").Where("slug = ?", slug).Find(&campaign).Error
+	if err != nil || campaign.ID != 0 {
+		return campaign.ID, err
+	}
+
+	history, err := r.FindSlugHistory(slug)
+	if err != nil {
+		return 0, err
+	}
+
+	return history.CampaignID, nil
+}
+
+// Save stores a new campaign. It returns ErrDuplicateSlug when another
+// campaign took its slug in the meantime.
 func (r *repository) Save(campaign Campaign) (Campaign, error) {
 	err := r.db.Create(&campaign).Error
 
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return campaign, ErrDuplicateSlug
+	}
+
 	if err != nil {
 		return campaign, err
 	}
@@ -65,8 +190,75 @@ func (r *repository) Save(campaign Campaign) (Campaign, error) {
 	return campaign, nil
 }
 
+// Update saves the owner's changes to a campaign. Only the columns an owner
+// edits are written, so a stale copy cannot undo a status change or the
+// totals of donations made in the meantime. The goal, funding model and
+// schedule are only written while the campaign is a draft; ErrCampaignChanged
+// is returned when it left draft since it was loaded.
+//
+// A new slug is written along with the rest, keeping the current one in the
+// history and taking a slug the campaign used before back out of it, and the
+// tags of the campaign are replaced by campaign.Tags. It returns
+// ErrDuplicateSlug when another campaign took the slug in the meantime.
 func (r *repository) Update(campaign Campaign) (Campaign, error) {
-	err := r.db.Save(&campaign).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current Campaign
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id, slug").Where("id = ?", campaign.ID).Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		if current.ID == 0 {
+			return ErrCampaignChanged
+		}
+
+		changes := map[string]interface{}{
+			"name":              campaign.Name,
+			"short_description": campaign.ShortDescription,
+			"description":       campaign.Description,
+			"category_id":       campaign.CategoryID,
+		}
+
+		if campaign.Slug != current.Slug {
+			err = tx.Where("campaign_id = ? AND slug = ?", campaign.ID, campaign.Slug).Delete(&CampaignSlugHistory{}).Error
+			if err != nil {
+				return err
+			}
+
+			if current.Slug != "" {
+				err = tx.Create(&CampaignSlugHistory{CampaignID: campaign.ID, Slug: current.Slug}).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			changes["slug"] = campaign.Slug
+		}
+
+		query := tx.Model(&Campaign{}).Where("id = ?", campaign.ID)
+		if campaign.Status == StatusDraft {
+			changes["goal_amount"] = campaign.GoalAmount
+			changes["funding_model"] = campaign.FundingModel
+			changes["starts_at"] = campaign.StartsAt
+			changes["ends_at"] = campaign.EndsAt
+			query = query.Where("status = ?", StatusDraft)
+		}
+
+		result := query.Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrCampaignChanged
+		}
+
+		return replaceTags(tx, campaign.ID, campaign.Tags)
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return campaign, ErrDuplicateSlug
+	}
 
 	if err != nil {
 		return campaign, err
@@ -75,6 +267,11 @@ func (r *repository) Update(campaign Campaign) (Campaign, error) {
 	return campaign, nil
 }
 
+// SetStartsAt gives a campaign without a start date one.
+func (r *repository) SetStartsAt(campaign Campaign, startsAt time.Time) error {
+	return r.db.Model(&Campaign{}).Where("id = ? AND starts_at IS NULL", campaign.ID).Update("starts_at", startsAt).Error
+}
+
 func (r *repository) CreateImage(campaignImage CampaignImage) (CampaignImage, error) {
 	err := r.db.Create(&campaignImage).Error
 
@@ -94,3 +291,508 @@ func (r *repository) MarkAllImagesAsNonPrimary(campaignID int) (bool, error) {
 
 	return true, nil
 }
+
+func (r *repository) FindRewardTiersByCampaignID(campaignID int) ([]RewardTier, error) {
+	var rewardTiers []RewardTier
+	err := r.db.Where("campaign_id = ?", campaignID).Order("minimum_pledge ASC, id ASC").Find(&rewardTiers).Error
+
+	if err != nil {
+		return rewardTiers, err
+	}
+
+	return rewardTiers, nil
+}
+
+func (r *repository) FindRewardTierByID(ID int) (RewardTier, error) {
+	var rewardTier RewardTier
+	err := r.db.Where("id = ?", ID).Find(&rewardTier).Error
+
+	if err != nil {
+		return rewardTier, err
+	}
+
+	return rewardTier, nil
+}
+
+func (r *repository) SaveRewardTier(rewardTier RewardTier) (RewardTier, error) {
+	err := r.db.Create(&rewardTier).Error
+
+	if err != nil {
+		return rewardTier, err
+	}
+
+	return rewardTier, nil
+}
+
+// UpdateRewardTier saves the creator's changes to a tier. Reserved is left
+// alone since donations change it concurrently, and a limited quantity below
+// the current reservations is refused by the same statement.
+func (r *repository) UpdateRewardTier(rewardTier RewardTier) (RewardTier, error) {
+	result := r.db.Model(&RewardTier{}).
+		Where("id = ? AND (? = 0 OR reserved <= ?)", rewardTier.ID, rewardTier.LimitedQuantity, rewardTier.LimitedQuantity).
+		Updates(map[string]interface{}{
+			"title":              rewardTier.Title,
+			"description":        rewardTier.Description,
+			"minimum_pledge":     rewardTier.MinimumPledge,
+			"limited_quantity":   rewardTier.LimitedQuantity,
+			"estimated_delivery": rewardTier.EstimatedDelivery,
+			"shipping_required":  rewardTier.ShippingRequired,
+		})
+
+	if result.Error != nil {
+		return rewardTier, result.Error
+	}
+
+	current, err := r.FindRewardTierByID(rewardTier.ID)
+	if err != nil {
+		return current, err
+	}
+
+	// MySQL also reports no affected rows when nothing changed, so look at the
+	// stored tier to tell whether the guard refused the update.
+	if result.RowsAffected == 0 && rewardTier.LimitedQuantity != 0 && current.Reserved > rewardTier.LimitedQuantity {
+		return current, errors.New("The limited quantity cannot be lower than the rewards already reserved!")
+	}
+
+	return current, nil
+}
+
+// DeleteRewardTier deletes a tier as long as no donation holds one of its
+// rewards.
+func (r *repository) DeleteRewardTier(rewardTier RewardTier) error {
+	result := r.db.Where("id = ? AND reserved = 0", rewardTier.ID).Delete(&RewardTier{})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("Reward tiers that backers have already selected cannot be deleted!")
+	}
+
+	return nil
+}
+
+// ReserveRewardTier takes one reward of a tier. The check and the increment
+// are one statement, so concurrent donations can never oversell a limited
+// tier.
+func (r *repository) ReserveRewardTier(ID int) error {
+	result := r.db.Model(&RewardTier{}).
+		Where("id = ? AND (limited_quantity = 0 OR reserved < limited_quantity)", ID).
+		Update("reserved", gorm.Expr("reserved + 1"))
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRewardTierSoldOut
+	}
+
+	return nil
+}
+
+// ReleaseRewardTier gives a reserved reward back, e.g. when its donation was
+// never paid or has been refunded.
+func (r *repository) ReleaseRewardTier(ID int) error {
+	return r.db.Model(&RewardTier{}).
+		Where("id = ? AND reserved > 0", ID).
+		Update("reserved", gorm.Expr("reserved - 1")).Error
+}
+
+func (r *repository) FindByStatus(status string) ([]Campaign, error) {
+	var campaigns []Campaign
+	err := r.db.Where("status = ?", status).Preload("CampaignImages", "campaign_images.is_primary = ?", ISPRIMARY).Order("updated_at ASC").Find(&campaigns).Error
+
+	if err != nil {
+		return campaigns, err
+	}
+
+	return campaigns, nil
+}
+
+func (r *repository) FindLiveEndedBefore(before time.Time, limit int) ([]Campaign, error) {
+	var campaigns []Campaign
+	err := r.db.Where("status = ? AND ends_at <= ?", StatusLive, before).Order("ends_at ASC").Limit(limit).Find(&campaigns).Error
+
+	if err != nil {
+		return campaigns, err
+	}
+
+	return campaigns, nil
+}
+
+func (r *repository) FindFailedByFundingModel(fundingModel string) ([]Campaign, error) {
+	var campaigns []Campaign
+	err := r.db.Where("status = ? AND funding_model = ?", StatusFailed, fundingModel).Find(&campaigns).Error
+
+	if err != nil {
+		return campaigns, err
+	}
+
+	return campaigns, nil
+}
+
+// UpdateStatus moves a campaign from its current status to toStatus. It
+// reports false without changing anything when the stored status is no longer
+// campaign.Status, e.g. because another request got there first.
+func (r *repository) UpdateStatus(campaign Campaign, toStatus string) (bool, error) {
+	result := r.db.Model(&Campaign{}).Where("id = ? AND status = ?", campaign.ID, campaign.Status).Update("status", toStatus)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// replaceTags swaps the tags of a campaign for new ones named like tags.
+func replaceTags(tx *gorm.DB, campaignID int, tags []CampaignTag) error {
+	err := tx.Where("campaign_id = ?", campaignID).Delete(&CampaignTag{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	newTags := []CampaignTag{}
+	for _, tag := range tags {
+		newTags = append(newTags, CampaignTag{CampaignID: campaignID, Name: tag.Name})
+	}
+
+	return tx.Create(&newTags).Error
+}
+
+func (r *repository) FindCategories() ([]Category, error) {
+	var categories []Category
+	err := r.db.Order("name ASC").Find(&categories).Error
+
+	if err != nil {
+		return categories, err
+	}
+
+	return categories, nil
+}
+
+func (r *repository) FindCategoryByID(ID int) (Category, error) {
+	var category Category
+	err := r.db.Where("id = ?", ID).Find(&category).Error
+
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+func (r *repository) FindCategoryBySlug(slug string) (Category, error) {
+	var category Category
+	err := r.db.Where("slug = ?", slug).Find(&category).Error
+
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+func (r *repository) SaveCategory(category Category) (Category, error) {
+	err := r.db.Create(&category).Error
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+func (r *repository) UpdateCategory(category Category) (Category, error) {
+	err := r.db.Save(&category).Error
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+// DeleteCategory deletes a category that has neither subcategories nor
+// campaigns, and returns ErrCategoryInUse otherwise.
+func (r *repository) DeleteCategory(category Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		err := tx.Model(&Category{}).Where("parent_id = ?", category.ID).Count(&children).Error
+		if err != nil {
+			return err
+		}
+
+		var campaigns int64
+		err = tx.Model(&Campaign{}).Where("category_id = ?", category.ID).Count(&campaigns).Error
+		if err != nil {
+			return err
+		}
+
+		if children > 0 || campaigns > 0 {
+			return ErrCategoryInUse
+		}
+
+		return tx.Delete(&category).Error
+	})
+}
+
+// CountLiveByCategory returns the number of live campaigns directly in each
+// category, keyed by category ID.
+func (r *repository) CountLiveByCategory() (map[int]int, error) {
+	var rows []struct {
+		CategoryID int
+		Count      int
+	}
+
+	err := r.db.Model(&Campaign{}).Select("category_id, COUNT(*) AS count").Where("status = ? AND category_id <> 0", StatusLive).Group("category_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[int]int{}
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// FindCampaignUpdatesByCampaignID lists the updates of a campaign, newest
+// first.
+func (r *repository) FindCampaignUpdatesByCampaignID(campaignID int) ([]CampaignUpdate, error) {
+	var updates []CampaignUpdate
+	err := r.db.Preload("Images").Where("campaign_id = ?", campaignID).Order("id DESC").Find(&updates).Error
+
+	if err != nil {
+		return updates, err
+	}
+
+	return updates, nil
+}
+
+func (r *repository) FindCampaignUpdateByID(ID int) (CampaignUpdate, error) {
+	var update CampaignUpdate
+	err := r.db.Preload("Images").Where("id = ?", ID).Find(&update).Error
+
+	if err != nil {
+		return update, err
+	}
+
+	return update, nil
+}
+
+// SaveCampaignUpdate stores a new update and queues an email about it to
+// every one of recipients.
+func (r *repository) SaveCampaignUpdate(update CampaignUpdate, recipients []string) (CampaignUpdate, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&update).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		notifications := []CampaignUpdateNotification{}
+		for _, email := range recipients {
+			notifications = append(notifications, CampaignUpdateNotification{
+				CampaignID:       update.CampaignID,
+				CampaignUpdateID: update.ID,
+				Email:            email,
+				Status:           NotificationPending,
+				NextAttemptAt:    &now,
+			})
+		}
+
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		return tx.Omit(clause.Associations).CreateInBatches(&notifications, notificationInsertBatchSize).Error
+	})
+
+	if err != nil {
+		return update, err
+	}
+
+	return update, nil
+}
+
+func (r *repository) UpdateCampaignUpdate(update CampaignUpdate) (CampaignUpdate, error) {
+	err := r.db.Save(&update).Error
+	if err != nil {
+		return update, err
+	}
+
+	return update, nil
+}
+
+// DeleteCampaignUpdate deletes an update with its images and the emails about
+// it that have not been sent yet.
+func (r *repository) DeleteCampaignUpdate(update CampaignUpdate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("campaign_update_id = ?", update.ID).Delete(&CampaignUpdateImage{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("campaign_update_id = ? AND status = ?", update.ID, NotificationPending).Delete(&CampaignUpdateNotification{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&CampaignUpdate{}, update.ID).Error
+	})
+}
+
+// FindNotificationsDue returns pending update emails whose next try is due,
+// oldest first, with their campaign and update.
+func (r *repository) FindNotificationsDue(now time.Time, limit int) ([]CampaignUpdateNotification, error) {
+	var notifications []CampaignUpdateNotification
+	err := r.db.Preload("Campaign").Preload("CampaignUpdate").Where("status = ? AND next_attempt_at <= ?", NotificationPending, now).Order("id ASC").Limit(limit).Find(&notifications).Error
+
+	if err != nil {
+		return notifications, err
+	}
+
+	return notifications, nil
+}
+
+// MarkNotificationSent records that an update email went out.
+func (r *repository) MarkNotificationSent(notification CampaignUpdateNotification) error {
+	return r.db.Model(&CampaignUpdateNotification{}).Where("id = ? AND status = ?", notification.ID, NotificationPending).Updates(map[string]interface{}{
+		"status":   NotificationSent,
+		"attempts": gorm.Expr("attempts + 1"),
+	}).Error
+}
+
+// DeferNotification counts a failed try of an update email and puts off the
+// next one until nextAttempt.
+func (r *repository) DeferNotification(notification CampaignUpdateNotification, nextAttempt time.Time) error {
+	return r.db.Model(&CampaignUpdateNotification{}).Where("id = ? AND status = ?", notification.ID, NotificationPending).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      notification.LastError,
+		"next_attempt_at": nextAttempt,
+	}).Error
+}
+
+// FailNotification gives up on an update email after its last failed try.
+func (r *repository) FailNotification(notification CampaignUpdateNotification) error {
+	return r.db.Model(&CampaignUpdateNotification{}).Where("id = ? AND status = ?", notification.ID, NotificationPending).Updates(map[string]interface{}{
+		"status":          NotificationFailed,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      notification.LastError,
+		"next_attempt_at": nil,
+	}).Error
+}
+
+func (r *repository) CreateCampaignUpdateImage(image CampaignUpdateImage) (CampaignUpdateImage, error) {
+	err := r.db.Create(&image).Error
+	if err != nil {
+		return image, err
+	}
+
+	return image, nil
+}
+
+func applyFilter(db *gorm.DB, filter CampaignFilter) *gorm.DB {
+	if !filter.Unpublished {
+		db = db.Where("status IN ?", publicStatuses)
+	}
+
+	if filter.UserID != 0 {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+
+	if filter.MinGoal != 0 {
+		db = db.Where("goal_amount >= ?", filter.MinGoal)
+	}
+
+	if filter.MaxGoal != 0 {
+		db = db.Where("goal_amount <= ?", filter.MaxGoal)
+	}
+
+	if filter.MinFundedPercent != 0 {
+		db = db.Where("current_amount * 100 >= goal_amount * ?", filter.MinFundedPercent)
+	}
+
+	if filter.MaxFundedPercent != 0 {
+		db = db.Where("current_amount * 100 <= goal_amount * ?", filter.MaxFundedPercent)
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		db = db.Where("category_id IN ?", filter.CategoryIDs)
+	}
+
+	if len(filter.Tags) > 0 {
+		tagged := db.Session(&gorm.Session{NewDB: true}).Model(&CampaignTag{}).Select("campaign_id").Where("name IN ?", filter.Tags).Group("campaign_id").Having("COUNT(DISTINCT name) = ?", len(filter.Tags))
+		db = db.Where("id IN (?)", tagged)
+	}
+
+	return db
+}
+
+// applyPage orders campaigns by the sort key of page.Sort, then by ID, and
+// keeps the campaigns after the cursor. The sort keys match sortValue.
+func applyPage(db *gorm.DB, page PageQuery) *gorm.DB {
+	column := "id"
+	descending := true
+
+	switch page.Sort {
+	case SortMostFunded:
+		column = "current_amount"
+	case SortClosestToGoal:
+		column = fmt.Sprintf("IF(current_amount < goal_amount, goal_amount - current_amount, %d)", goalReachedValue)
+		descending = false
+	case SortEndingSoon:
+		column = "ends_at"
+		descending = false
+	case SortMostBackers:
+		column = "backer_count"
+	}
+
+	direction, comparison := "DESC", "<"
+	if !descending {
+		direction, comparison = "ASC", ">"
+	}
+
+	// Campaigns without an end date never end, so they come last.
+	if page.Sort == SortEndingSoon {
+		db = db.Order("ends_at IS NULL ASC")
+	}
+
+	if column != "id" {
+		db = db.Order(column + " " + direction)
+	}
+	db = db.Order("id " + direction)
+
+	if cursor := page.Cursor; cursor != nil {
+		switch {
+		case column == "id":
+			db = db.Where("id "+comparison+" ?", cursor.ID)
+		case page.Sort == SortEndingSoon && cursor.Value == noDeadlineValue:
+			db = db.Where("ends_at IS NULL AND id > ?", cursor.ID)
+		case page.Sort == SortEndingSoon:
+			endsAt := cursorTime(*cursor)
+			db = db.Where("ends_at > ? OR ends_at IS NULL OR (ends_at = ? AND id > ?)", endsAt, endsAt, cursor.ID)
+		default:
+			db = db.Where(column+" "+comparison+" ? OR ("+column+" = ? AND id "+comparison+" ?)", cursor.Value, cursor.Value, cursor.ID)
+		}
+	}
+
+	if page.Offset > 0 {
+		db = db.Offset(page.Offset)
+	}
+
+	if page.Limit > 0 {
+		db = db.Limit(page.Limit + 1)
+	}
+
+	return db
+}
@@ -0,0 +1,386 @@
+package campaign
+
+import (
+	"html"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchIndex finds public campaigns matching a free-text query. Campaigns
+// are indexed when they become public and whenever they change, and removed
+// when they stop being public.
+type SearchIndex interface {
+	Index(document SearchDocument) error
+	Remove(campaignID int) error
+	Search(query string, limit int) ([]SearchHit, error)
+}
+
+// SearchDocument holds the searchable text of a campaign.
+type SearchDocument struct {
+	CampaignID       int
+	Name             string
+	ShortDescription string
+	Description      string
+	Tags             []string
+}
+
+// SearchHit is a campaign matching a search, with its name and a snippet of
+// its description where the matched words are wrapped in <mark> tags. The
+// rest of the text is HTML-escaped.
+type SearchHit struct {
+	CampaignID      int
+	Score           float64
+	HighlightedName string
+	Snippet         string
+}
+
+// SearchResult is a campaign found by a search.
+type SearchResult struct {
+	Campaign Campaign
+	Hit      SearchHit
+}
+
+const (
+	maxQueryTerms  = 10
+	minTermLength  = 2
+	snippetLength  = 160
+	snippetLeadIn  = 40
+	snippetEllipse = "…"
+)
+
+// Field weights: a match in the name counts more than one in the tags, which
+// in turn counts more than one in the descriptions.
+const (
+	nameWeight             = 4.0
+	tagWeight              = 3.0
+	shortDescriptionWeight = 2.0
+	descriptionWeight      = 1.0
+)
+
+// Match qualities of a query term against a word of a document.
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.8
+	typoMatch   = 0.6
+)
+
+func NewSearchDocument(campaign Campaign) SearchDocument {
+	document := SearchDocument{
+		CampaignID:       campaign.ID,
+		Name:             campaign.Name,
+		ShortDescription: campaign.ShortDescription,
+		Description:      campaign.Description,
+		Tags:             []string{},
+	}
+
+	for _, tag := range campaign.Tags {
+		document.Tags = append(document.Tags, tag.Name)
+	}
+
+	return document
+}
+
+// token is a word of a text with its byte offsets.
+type token struct {
+	word  string
+	start int
+	end   int
+}
+
+func tokenize(text string) []token {
+	tokens := []token{}
+	start := -1
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// parseQuery returns the distinct lowercase words of a query, ignoring words
+// too short to be meaningful.
+func parseQuery(query string) []string {
+	terms := []string{}
+
+	for _, t := range tokenize(query) {
+		if utf8.RuneCountInString(t.word) < minTermLength || slices.Contains(terms, t.word) {
+			continue
+		}
+
+		terms = append(terms, t.word)
+		if len(terms) == maxQueryTerms {
+			break
+		}
+	}
+
+	return terms
+}
+
+// matchTerm rates how well a query term matches a word: exactly, as the
+// beginning of the word, or with a typo. Longer terms tolerate more typos.
+func matchTerm(term string, word string) float64 {
+	if term == word {
+		return exactMatch
+	}
+
+	termLength := utf8.RuneCountInString(term)
+	if termLength >= 3 && strings.HasPrefix(word, term) {
+		return prefixMatch
+	}
+
+	maxDistance := 0
+	switch {
+	case termLength >= 8:
+		maxDistance = 2
+	case termLength >= 4:
+		maxDistance = 1
+	}
+
+	if maxDistance > 0 && editDistance(term, word, maxDistance) <= maxDistance {
+		return typoMatch
+	}
+
+	return 0
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b
+// (optimal string alignment), or max+1 as soon as it is known to exceed max.
+func editDistance(a string, b string, max int) int {
+	s, t := []rune(a), []rune(b)
+	if abs(len(s)-len(t)) > max {
+		return max + 1
+	}
+
+	previous2 := make([]int, len(t)+1)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		rowMin := current[0]
+
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+
+			rowMin = min(rowMin, current[j])
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+
+		previous2, previous, current = previous, current, previous2
+	}
+
+	return previous[len(t)]
+}
+
+// bestMatch returns the best match of term among tokens.
+func bestMatch(term string, tokens []token) float64 {
+	best := 0.0
+
+	for _, t := range tokens {
+		best = max(best, matchTerm(term, t.word))
+		if best == exactMatch {
+			break
+		}
+	}
+
+	return best
+}
+
+// rankDocument scores a document against the query terms. Every term counts
+// with its best match over all fields, weighted by field, and documents
+// matching only some of the terms are ranked down accordingly. It reports
+// false when no term matches at all.
+func rankDocument(document SearchDocument, terms []string) (SearchHit, bool) {
+	nameTokens := tokenize(document.Name)
+	tagTokens := tokenize(strings.Join(document.Tags, " "))
+	shortDescriptionTokens := tokenize(document.ShortDescription)
+	descriptionTokens := tokenize(document.Description)
+
+	score := 0.0
+	matched := 0
+	shortDescriptionScore := 0.0
+	descriptionScore := 0.0
+
+	for _, term := range terms {
+		shortDescriptionMatch := bestMatch(term, shortDescriptionTokens)
+		descriptionMatch := bestMatch(term, descriptionTokens)
+		shortDescriptionScore += shortDescriptionMatch
+		descriptionScore += descriptionMatch
+
+		termScore := max(
+			nameWeight*bestMatch(term, nameTokens),
+			tagWeight*bestMatch(term, tagTokens),
+			shortDescriptionWeight*shortDescriptionMatch,
+			descriptionWeight*descriptionMatch,
+		)
+
+		if termScore > 0 {
+			score += termScore
+			matched++
+		}
+	}
+
+	if matched == 0 {
+		return SearchHit{}, false
+	}
+
+	hit := SearchHit{
+		CampaignID:      document.CampaignID,
+		Score:           score * float64(matched) / float64(len(terms)),
+		HighlightedName: highlight(document.Name, nameTokens, terms, 0, len(document.Name)),
+	}
+
+	if descriptionScore > shortDescriptionScore {
+		hit.Snippet = snippet(document.Description, descriptionTokens, terms)
+	} else {
+		hit.Snippet = snippet(document.ShortDescription, shortDescriptionTokens, terms)
+	}
+
+	return hit, true
+}
+
+// snippet cuts a window of text around its first matched word and
+// highlights the matches in it.
+func snippet(text string, tokens []token, terms []string) string {
+	first := -1
+	for i, t := range tokens {
+		if matchesAny(t.word, terms) {
+			first = i
+			break
+		}
+	}
+
+	start := 0
+	if first >= 0 {
+		start = tokens[first].start
+		for i := first; i >= 0 && tokens[first].start-tokens[i].start <= snippetLeadIn; i-- {
+			start = tokens[i].start
+		}
+	}
+
+	end := len(text)
+	for _, t := range tokens {
+		if t.start >= start && t.end-start > snippetLength {
+			end = t.start
+			break
+		}
+	}
+
+	result := highlight(text, tokens, terms, start, end)
+	result = strings.TrimSpace(result)
+
+	if start > 0 {
+		result = snippetEllipse + result
+	}
+
+	if end < len(text) {
+		result = result + snippetEllipse
+	}
+
+	return result
+}
+
+// highlight HTML-escapes text[start:end] and wraps the words matching a
+// query term in <mark> tags.
+func highlight(text string, tokens []token, terms []string, start int, end int) string {
+	var builder strings.Builder
+	position := start
+
+	for _, t := range tokens {
+		if t.start < start || t.end > end || !matchesAny(t.word, terms) {
+			continue
+		}
+
+		builder.WriteString(html.EscapeString(text[position:t.start]))
+		builder.WriteString("<mark>")
+		builder.WriteString(html.EscapeString(text[t.start:t.end]))
+		builder.WriteString("</mark>")
+		position = t.end
+	}
+
+	builder.WriteString(html.EscapeString(text[position:end]))
+
+	return builder.String()
+}
+
+func matchesAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if matchTerm(term, word) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rankDocuments scores documents against query and returns the best limit
+// matches, highest score first.
+func rankDocuments(documents []SearchDocument, query string, limit int) []SearchHit {
+	terms := parseQuery(query)
+	hits := []SearchHit{}
+
+	if len(terms) == 0 {
+		return hits
+	}
+
+	for _, document := range documents {
+		hit, ok := rankDocument(document, terms)
+		if ok {
+			hits = append(hits, hit)
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return hits[i].CampaignID > hits[j].CampaignID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
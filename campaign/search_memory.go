@@ -0,0 +1,44 @@
+package campaign
+
+import "sync"
+
+// memorySearchIndex keeps every document in memory and ranks all of them on
+// each search. It needs no setup, which suits development, but it is rebuilt
+// from the database on every start and does not scale to many campaigns.
+type memorySearchIndex struct {
+	mu        sync.RWMutex
+	documents map[int]SearchDocument
+}
+
+func NewMemorySearchIndex() *memorySearchIndex {
+	return &memorySearchIndex{documents: map[int]SearchDocument{}}
+}
+
+func (i *memorySearchIndex) Index(document SearchDocument) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.documents[document.CampaignID] = document
+
+	return nil
+}
+
+func (i *memorySearchIndex) Remove(campaignID int) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.documents, campaignID)
+
+	return nil
+}
+
+func (i *memorySearchIndex) Search(query string, limit int) ([]SearchHit, error) {
+	i.mu.RLock()
+	documents := make([]SearchDocument, 0, len(i.documents))
+	for _, document := range i.documents {
+		documents = append(documents, document)
+	}
+	i.mu.RUnlock()
+
+	return rankDocuments(documents, query, limit), nil
+}
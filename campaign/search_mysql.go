@@ -0,0 +1,92 @@
+package campaign
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mysqlCandidateLimit is how many FULLTEXT matches are ranked per search.
+const mysqlCandidateLimit = 200
+
+// mysqlSearchIndex searches the campaigns table itself with the FULLTEXT
+// index on name, short_description and description, so there is nothing to
+// keep in sync and Index and Remove do nothing. MySQL picks the candidates,
+// matching every term and its beginning so that typos near the end of a word
+// are still found, and the candidates are then ranked like in the in-memory
+// index, which also catches typos elsewhere in tags and scores every field
+// consistently.
+type mysqlSearchIndex struct {
+	db *gorm.DB
+}
+
+func NewMySQLSearchIndex(db *gorm.DB) *mysqlSearchIndex {
+	return &mysqlSearchIndex{db}
+}
+
+func (i *mysqlSearchIndex) Index(document SearchDocument) error {
+	return nil
+}
+
+func (i *mysqlSearchIndex) Remove(campaignID int) error {
+	return nil
+}
+
+func (i *mysqlSearchIndex) Search(query string, limit int) ([]SearchHit, error) {
+	terms := parseQuery(query)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+
+	booleanQuery := []string{}
+	tagPatterns := []string{}
+	for _, term := range terms {
+		prefix := termPrefix(term)
+		booleanQuery = append(booleanQuery, term, prefix+"*")
+		tagPatterns = append(tagPatterns, prefix+"%")
+	}
+
+	against := strings.Join(booleanQuery, " ")
+
+	tagged := i.db.Model(&CampaignTag{}).Select("campaign_id")
+	for _, pattern := range tagPatterns {
+		tagged = tagged.Or("name LIKE ?", pattern)
+	}
+
+	var campaigns []Campaign
+	err := i.db.Select("id, name, short_description, description").
+		Where("status IN ?", publicStatuses).
+		Where(i.db.Where("MATCH(name, short_description, description) AGAINST (? IN BOOLEAN MODE)", against).Or("id IN (?)", tagged)).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "MATCH(name, short_description, description) AGAINST (? IN BOOLEAN MODE) DESC", Vars: []interface{}{against}, WithoutParentheses: true}}).
+		Limit(mysqlCandidateLimit).
+		Preload("Tags").
+		Find(&campaigns).Error
+	if err != nil {
+		return []SearchHit{}, err
+	}
+
+	documents := []SearchDocument{}
+	for _, campaign := range campaigns {
+		documents = append(documents, NewSearchDocument(campaign))
+	}
+
+	return rankDocuments(documents, query, limit), nil
+}
+
+// termPrefix shortens longer terms by the number of typos they tolerate, so
+// that a prefix search still finds words misspelled near their end. Only
+// letters and digits remain, so the term cannot inject boolean operators.
+func termPrefix(term string) string {
+	runes := []rune(term)
+
+	switch length := utf8.RuneCountInString(term); {
+	case length >= 8:
+		runes = runes[:length-2]
+	case length >= 4:
+		runes = runes[:length-1]
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,163 @@
+package campaign
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"water", "water", 2, 0},
+		{"water", "wate", 2, 1},
+		{"water", "waiter", 2, 1},
+		{"water", "wader", 2, 1},
+		{"water", "wtaer", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3},
+		{"school", "skool", 1, 2},
+		{"abc", "abcdef", 1, 2},
+		{"café", "cafe", 1, 1},
+		{"", "ab", 2, 2},
+	}
+
+	for _, test := range tests {
+		got := editDistance(test.a, test.b, test.max)
+		if got != test.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", test.a, test.b, test.max, got, test.want)
+		}
+	}
+}
+
+func TestMemorySearchIndexRanking(t *testing.T) {
+	index := NewMemorySearchIndex()
+	documents := []SearchDocument{
+		{CampaignID: 1, Name: "Clean water for Sumba", ShortDescription: "Wells for three villages", Tags: []string{"health"}},
+		{CampaignID: 2, Name: "School library", ShortDescription: "Books for every pupil", Description: "The roof lets the water in.", Tags: []string{"education"}},
+		{CampaignID: 3, Name: "Community garden", ShortDescription: "Vegetables for the neighbourhood", Tags: []string{"water", "food"}},
+		{CampaignID: 4, Name: "Water filters", ShortDescription: "Safe drinking water at home", Tags: []string{"health"}},
+		{CampaignID: 5, Name: "Old water pump", ShortDescription: "Removed before the search"},
+	}
+
+	for _, document := range documents {
+		err := index.Index(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := index.Remove(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []int
+	}{
+		// Name matches outrank tag matches, which outrank description
+		// matches; equal scores put the newer campaign first.
+		{"field weights", "water", 0, []int{4, 1, 3, 2}},
+		{"limit", "water", 2, []int{4, 1}},
+		{"all terms first", "clean water", 0, []int{1, 4, 3, 2}},
+		{"prefix", "libr", 0, []int{2}},
+		{"typo", "gardne", 0, []int{3}},
+		{"transposed letters", "healht", 0, []int{4, 1}},
+		{"case and punctuation", "SCHOOL!", 0, []int{2}},
+		{"short terms ignored", "a", 0, []int{}},
+		{"no match", "bicycle", 0, []int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hits, err := index.Search(test.query, test.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []int{}
+			for _, hit := range hits {
+				got = append(got, hit.CampaignID)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMemorySearchIndexHighlighting(t *testing.T) {
+	long := "Our village has waited for years. " + strings.Repeat("Every season the road floods and the children stay home. ", 2) +
+		"This campaign builds a bridge over the river so <everyone> can reach the school. " + strings.Repeat("Thank you for your support. ", 4)
+
+	tests := []struct {
+		name        string
+		document    SearchDocument
+		query       string
+		wantName    string
+		wantSnippet string
+	}{
+		{
+			name:        "short description",
+			document:    SearchDocument{CampaignID: 1, Name: "Clean water for Sumba", ShortDescription: "Fresh water & wells for three villages"},
+			query:       "water",
+			wantName:    "Clean <mark>water</mark> for Sumba",
+			wantSnippet: "Fresh <mark>water</mark> &amp; wells for three villages",
+		},
+		{
+			name:        "typo highlighted",
+			document:    SearchDocument{CampaignID: 1, Name: "Village library", ShortDescription: "Books for the library"},
+			query:       "libary",
+			wantName:    "Village <mark>library</mark>",
+			wantSnippet: "Books for the <mark>library</mark>",
+		},
+		{
+			name:        "window around the first match",
+			document:    SearchDocument{CampaignID: 1, Name: "River crossing", ShortDescription: "A safer way to school", Description: long},
+			query:       "bridge river",
+			wantName:    "<mark>River</mark> crossing",
+			wantSnippet: "…stay home. This campaign builds a <mark>bridge</mark> over the <mark>river</mark> so &lt;everyone&gt; can reach the school. Thank you for your support. Thank you for your support. Thank you…",
+		},
+		{
+			name:        "start of a long text",
+			document:    SearchDocument{CampaignID: 1, Name: "Village bridge", ShortDescription: "", Description: long},
+			query:       "village",
+			wantName:    "<mark>Village</mark> bridge",
+			wantSnippet: "Our <mark>village</mark> has waited for years. Every season the road floods and the children stay home. Every season the road floods and the children stay home. This…",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index := NewMemorySearchIndex()
+			err := index.Index(test.document)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hits, err := index.Search(test.query, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(hits) != 1 {
+				t.Fatalf("Search(%q) found %d campaigns, want 1", test.query, len(hits))
+			}
+
+			if hits[0].HighlightedName != test.wantName {
+				t.Errorf("highlighted name = %q, want %q", hits[0].HighlightedName, test.wantName)
+			}
+
+			if hits[0].Snippet != test.wantSnippet {
+				t.Errorf("snippet = %q, want %q", hits[0].Snippet, test.wantSnippet)
+			}
+		})
+	}
+}
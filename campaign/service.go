@@ -1,55 +1,270 @@
 package campaign
 
 import (
+	"cfa-backend/user"
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gosimple/slug"
 )
 
 type Service interface {
-	GetCampaigns(userID int) ([]Campaign, error)
-	GetCampaignByID(input GetCampaignDetailInput) (Campaign, error)
+	GetCampaigns(input GetCampaignsInput) ([]Campaign, Page, error)
+	GetUserCampaigns(input GetCampaignsInput, currentUser user.User) ([]Campaign, Page, error)
+	SearchCampaigns(input SearchCampaignsInput) ([]SearchResult, error)
+	RebuildSearchIndex() (int, error)
+	GetCampaignByID(input GetCampaignDetailInput, currentUser user.User) (Campaign, error)
+	GetCampaignBySlug(input GetCampaignBySlugInput, currentUser user.User) (Campaign, error)
 	CreateCampaign(input CreateCampaignInput) (Campaign, error)
 	UpdateCampaign(inputURI GetCampaignDetailInput, input CreateCampaignInput) (Campaign, error)
 	SaveCampaignImage(input CreateCampaignImageInput, filePath string) (CampaignImage, error)
+	GetRewardTiers(input GetCampaignDetailInput, currentUser user.User) ([]RewardTier, error)
+	CreateRewardTier(inputURI GetCampaignDetailInput, input RewardTierInput) (RewardTier, error)
+	UpdateRewardTier(inputURI GetRewardTierInput, input RewardTierInput) (RewardTier, error)
+	DeleteRewardTier(inputURI GetRewardTierInput, currentUser user.User) error
+	SubmitCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error)
+	ApproveCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error)
+	RejectCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error)
+	CloseCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error)
+	GetCampaignsByStatus(status string) ([]Campaign, error)
+	FinishEndedCampaigns(ctx context.Context) (int, error)
+	GetCampaignsAwaitingRefund() ([]Campaign, error)
+	MarkCampaignRefunded(campaign Campaign) error
+	GetCategories() ([]CategoryNode, error)
+	GetCategory(input GetCategoryInput) (CategoryNode, error)
+	CreateCategory(input CategoryInput) (Category, error)
+	UpdateCategory(inputURI GetCategoryInput, input CategoryInput) (Category, error)
+	DeleteCategory(inputURI GetCategoryInput, currentUser user.User) error
+	GetCampaignUpdates(input GetCampaignDetailInput, currentUser user.User) ([]CampaignUpdate, bool, error)
+	SendUpdateNotifications(ctx context.Context) (int, error)
+	GetCampaignUpdate(input GetCampaignUpdateInput, currentUser user.User) (CampaignUpdate, bool, error)
+	CreateCampaignUpdate(inputURI GetCampaignDetailInput, input CampaignUpdateInput) (CampaignUpdate, error)
+	UpdateCampaignUpdate(inputURI GetCampaignUpdateInput, input CampaignUpdateInput) (CampaignUpdate, error)
+	DeleteCampaignUpdate(inputURI GetCampaignUpdateInput, currentUser user.User) error
+	SaveCampaignUpdateImage(inputURI GetCampaignUpdateInput, currentUser user.User, filePath string) (CampaignUpdateImage, error)
 }
 
+// finishBatchSize is how many ended campaigns are finished per worker run.
+const finishBatchSize = 100
+
+// notificationBatchSize is how many update emails are sent per worker run,
+// notificationSenders of them at the same time. A failed email is tried again
+// after notificationRetryDelay, doubled with every try, up to
+// maxNotificationAttempts times.
+const (
+	notificationBatchSize   = 500
+	notificationSenders     = 8
+	notificationRetryDelay  = 10 * time.Minute
+	maxNotificationAttempts = 5
+)
+
+// defaultSearchLimit is how many results a search returns unless asked for
+// fewer or more.
+const defaultSearchLimit = 20
+
+const defaultPageLimit = 20
+
 type service struct {
-	repository Repository
+	repository      Repository
+	searchIndex     SearchIndex
+	backerDirectory BackerDirectory
 }
 
-func NewService(repository Repository) *service {
-	return &service{repository: repository}
+func NewService(repository Repository, searchIndex SearchIndex, backerDirectory BackerDirectory) *service {
+	return &service{repository: repository, searchIndex: searchIndex, backerDirectory: backerDirectory}
 }
 
-func (s *service) GetCampaigns(userID int) ([]Campaign, error) {
-	var campaigns []Campaign
-	var err error
+// GetCampaigns returns one page of the public campaigns, optionally of one
+// creator, in a category or one of its subcategories, carrying all of the
+// given tags and within the given goal and funded ranges. Pages are requested
+// either by number or by the cursor of the previous page.
+func (s *service) GetCampaigns(input GetCampaignsInput) ([]Campaign, Page, error) {
+	filter, query, err := parseListInput(input)
+	if err != nil {
+		return []Campaign{}, Page{}, err
+	}
+
+	return s.listCampaigns(input, filter, query)
+}
+
+// GetUserCampaigns returns one page of the campaigns of the current user,
+// drafts and campaigns under review included, filtered and sorted like
+// GetCampaigns.
+func (s *service) GetUserCampaigns(input GetCampaignsInput, currentUser user.User) ([]Campaign, Page, error) {
+	input.UserID = currentUser.ID
+
+	filter, query, err := parseListInput(input)
+	if err != nil {
+		return []Campaign{}, Page{}, err
+	}
+	filter.Unpublished = true
+
+	return s.listCampaigns(input, filter, query)
+}
 
-	if userID != 0 {
-		campaigns, err = s.repository.FindByUserID(userID)
+// listCampaigns narrows filter down to the category asked for, subcategories
+// included, and loads the page of campaigns matching it.
+func (s *service) listCampaigns(input GetCampaignsInput, filter CampaignFilter, query PageQuery) ([]Campaign, Page, error) {
+	if input.Category != "" {
+		category, err := s.repository.FindCategoryBySlug(input.Category)
 		if err != nil {
-			return campaigns, err
+			return []Campaign{}, Page{}, err
 		}
-	} else {
-		campaigns, err = s.repository.FindAll()
+
+		if category.ID == 0 {
+			return []Campaign{}, Page{}, errors.New("No category found with that slug")
+		}
+
+		categories, err := s.repository.FindCategories()
 		if err != nil {
-			return campaigns, err
+			return []Campaign{}, Page{}, err
 		}
+
+		filter.CategoryIDs = descendantIDs(categories, category.ID)
 	}
 
-	return campaigns, nil
+	campaigns, err := s.repository.FindAll(filter, query)
+	if err != nil {
+		return campaigns, Page{}, err
+	}
+
+	total, err := s.repository.CountAll(filter)
+	if err != nil {
+		return campaigns, Page{}, err
+	}
+
+	campaigns, page := paginate(campaigns, query)
+	page.Total = total
+	page.Number = input.Page
+
+	return campaigns, page, nil
+}
+
+// SearchCampaigns returns the public campaigns matching a free-text query,
+// most relevant first.
+func (s *service) SearchCampaigns(input SearchCampaignsInput) ([]SearchResult, error) {
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	hits, err := s.searchIndex.Search(input.Query, limit)
+	if err != nil {
+		return []SearchResult{}, err
+	}
+
+	IDs := []int{}
+	for _, hit := range hits {
+		IDs = append(IDs, hit.CampaignID)
+	}
+
+	campaigns, err := s.repository.FindByIDs(IDs)
+	if err != nil {
+		return []SearchResult{}, err
+	}
+
+	campaignsByID := map[int]Campaign{}
+	for _, campaign := range campaigns {
+		campaignsByID[campaign.ID] = campaign
+	}
+
+	// A campaign may have stopped being public since it was indexed.
+	results := []SearchResult{}
+	for _, hit := range hits {
+		campaign, found := campaignsByID[hit.CampaignID]
+		if found {
+			results = append(results, SearchResult{Campaign: campaign, Hit: hit})
+		}
+	}
+
+	return results, nil
+}
+
+// RebuildSearchIndex indexes every public campaign, e.g. to fill an
+// in-memory index on start. It returns how many campaigns were indexed.
+func (s *service) RebuildSearchIndex() (int, error) {
+	campaigns, err := s.repository.FindAll(CampaignFilter{}, PageQuery{})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, campaign := range campaigns {
+		err = s.searchIndex.Index(NewSearchDocument(campaign))
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(campaigns), nil
 }
 
-func (s *service) GetCampaignByID(input GetCampaignDetailInput) (Campaign, error) {
+// GetCampaignByID returns a campaign with its updates for the detail page.
+// Campaigns that are not public yet are only found by their owner and admins.
+func (s *service) GetCampaignByID(input GetCampaignDetailInput, currentUser user.User) (Campaign, error) {
 	campaign, err := s.repository.FindByID(input.ID)
 
 	if err != nil {
 		return campaign, err
 	}
 
-	return campaign, err
+	if campaign.ID == 0 || !campaign.VisibleTo(currentUser) {
+		return Campaign{}, errors.New("No campaign found with that ID")
+	}
+
+	return s.withUpdates(campaign)
+}
+
+// GetCampaignBySlug returns the campaign using slug, or the campaign that used
+// it before being renamed; the caller can tell the two apart by comparing the
+// slug of the campaign with the one asked for. Like GetCampaignByID, campaigns
+// that are not public yet are only found by their owner and admins.
+func (s *service) GetCampaignBySlug(input GetCampaignBySlugInput, currentUser user.User) (Campaign, error) {
+	campaign, err := s.repository.FindBySlug(input.Slug)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		history, err := s.repository.FindSlugHistory(input.Slug)
+		if err != nil {
+			return campaign, err
+		}
+
+		if history.ID != 0 {
+			campaign, err = s.repository.FindByID(history.CampaignID)
+			if err != nil {
+				return campaign, err
+			}
+		}
+	}
+
+	if campaign.ID == 0 || !campaign.VisibleTo(currentUser) {
+		return Campaign{}, errors.New("No campaign found with that slug")
+	}
+
+	return s.withUpdates(campaign)
+}
+
+// withUpdates loads the updates of a campaign, newest first. Only the
+// campaign detail shows them, so FindByID leaves them out.
+func (s *service) withUpdates(campaign Campaign) (Campaign, error) {
+	if campaign.ID == 0 {
+		return campaign, nil
+	}
+
+	updates, err := s.repository.FindCampaignUpdatesByCampaignID(campaign.ID)
+	if err != nil {
+		return campaign, err
+	}
+	campaign.Updates = updates
+
+	return campaign, nil
 }
 
 func (s *service) CreateCampaign(input CreateCampaignInput) (Campaign, error) {
@@ -57,21 +272,39 @@ func (s *service) CreateCampaign(input CreateCampaignInput) (Campaign, error) {
 		Name:             input.Name,
 		ShortDescription: input.ShortDescription,
 		Description:      input.Description,
-		Perks:            input.Perks,
 		GoalAmount:       input.GoalAmount,
 		UserID:           input.User.ID,
+		Status:           StatusDraft,
+		FundingModel:     input.FundingModel,
+		StartsAt:         input.StartsAt,
+		EndsAt:           input.EndsAt,
+		CategoryID:       input.CategoryID,
+	}
+
+	for _, name := range normalizeTags(input.Tags) {
+		campaign.Tags = append(campaign.Tags, CampaignTag{Name: name})
+	}
+
+	if campaign.FundingModel == "" {
+		campaign.FundingModel = FundingKeepWhatYouRaise
+	}
+
+	err := validateSchedule(campaign.StartsAt, campaign.EndsAt)
+	if err != nil {
+		return campaign, err
 	}
 
-	//set Slug
-	preSlug := fmt.Sprintf("%s %d", input.Name, input.User.ID)
-	campaign.Slug = slug.Make(preSlug)
+	err = s.validateCategory(campaign.CategoryID)
+	if err != nil {
+		return campaign, err
+	}
 
-	newCmmpaign, err := s.repository.Save(campaign)
+	newCmmpaign, err := s.saveWithSlug(campaign, s.repository.Save)
 	if err != nil {
 		return newCmmpaign, err
 	}
 
-	return newCmmpaign, nil
+	return s.repository.FindByID(newCmmpaign.ID)
 }
 
 func (s *service) UpdateCampaign(inputURI GetCampaignDetailInput, input CreateCampaignInput) (Campaign, error) {
@@ -85,17 +318,60 @@ func (s *service) UpdateCampaign(inputURI GetCampaignDetailInput, input CreateCa
 		return campaign, errors.New("You do not have authorization for change the campaign!")
 	}
 
+	err = s.validateCategory(input.CategoryID)
+	if err != nil {
+		return campaign, err
+	}
+
+	renamed := campaign.Name != input.Name
+
 	campaign.Name = input.Name
 	campaign.ShortDescription = input.ShortDescription
 	campaign.Description = input.Description
-	campaign.Perks = input.Perks
-	campaign.GoalAmount = input.GoalAmount
+	campaign.CategoryID = input.CategoryID
+
+	// The goal, funding model and schedule are what backers pledge against,
+	// so they are fixed once the campaign has been submitted for review.
+	if campaign.Status == StatusDraft {
+		campaign.GoalAmount = input.GoalAmount
+		campaign.StartsAt = input.StartsAt
+		campaign.EndsAt = input.EndsAt
 
-	updatedCampaign, err := s.repository.Update(campaign)
+		if input.FundingModel != "" {
+			campaign.FundingModel = input.FundingModel
+		}
+
+		err = validateSchedule(campaign.StartsAt, campaign.EndsAt)
+		if err != nil {
+			return campaign, err
+		}
+	} else if input.GoalAmount != campaign.GoalAmount || (input.FundingModel != "" && input.FundingModel != campaign.FundingModel) {
+		return campaign, errors.New("The goal and funding model can only be changed while the campaign is a draft!")
+	}
+
+	campaign.Tags = []CampaignTag{}
+	for _, name := range normalizeTags(input.Tags) {
+		campaign.Tags = append(campaign.Tags, CampaignTag{Name: name})
+	}
+
+	var updatedCampaign Campaign
+	if renamed {
+		updatedCampaign, err = s.saveWithSlug(campaign, s.repository.Update)
+	} else {
+		updatedCampaign, err = s.repository.Update(campaign)
+	}
+
+	if err != nil {
+		return updatedCampaign, err
+	}
+
+	updatedCampaign, err = s.repository.FindByID(updatedCampaign.ID)
 	if err != nil {
 		return updatedCampaign, err
 	}
 
+	s.syncSearchIndex(updatedCampaign)
+
 	return updatedCampaign, nil
 }
 
@@ -132,3 +408,951 @@ func (s *service) SaveCampaignImage(input CreateCampaignImageInput, filePath str
 
 	return newCampaignImage, nil
 }
+
+// GetRewardTiers lists the reward tiers of a campaign that currentUser may see.
+func (s *service) GetRewardTiers(input GetCampaignDetailInput, currentUser user.User) ([]RewardTier, error) {
+	campaign, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return []RewardTier{}, err
+	}
+
+	if campaign.ID == 0 || !campaign.VisibleTo(currentUser) {
+		return []RewardTier{}, errors.New("No campaign found with that ID")
+	}
+
+	rewardTiers, err := s.repository.FindRewardTiersByCampaignID(input.ID)
+	if err != nil {
+		return rewardTiers, err
+	}
+
+	return rewardTiers, nil
+}
+
+func (s *service) CreateRewardTier(inputURI GetCampaignDetailInput, input RewardTierInput) (RewardTier, error) {
+	campaign, err := s.repository.FindByID(inputURI.ID)
+	if err != nil {
+		return RewardTier{}, err
+	}
+
+	if campaign.ID == 0 {
+		return RewardTier{}, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != input.User.ID {
+		return RewardTier{}, errors.New("You do not have authorization for change the campaign!")
+	}
+
+	rewardTier := RewardTier{CampaignID: campaign.ID}
+	rewardTier, err = applyRewardTierInput(rewardTier, input)
+	if err != nil {
+		return rewardTier, err
+	}
+
+	newRewardTier, err := s.repository.SaveRewardTier(rewardTier)
+	if err != nil {
+		return newRewardTier, err
+	}
+
+	return newRewardTier, nil
+}
+
+func (s *service) UpdateRewardTier(inputURI GetRewardTierInput, input RewardTierInput) (RewardTier, error) {
+	rewardTier, err := s.findManagedRewardTier(inputURI, input.User)
+	if err != nil {
+		return rewardTier, err
+	}
+
+	rewardTier, err = applyRewardTierInput(rewardTier, input)
+	if err != nil {
+		return rewardTier, err
+	}
+
+	updatedRewardTier, err := s.repository.UpdateRewardTier(rewardTier)
+	if err != nil {
+		return updatedRewardTier, err
+	}
+
+	return updatedRewardTier, nil
+}
+
+func (s *service) DeleteRewardTier(inputURI GetRewardTierInput, currentUser user.User) error {
+	rewardTier, err := s.findManagedRewardTier(inputURI, currentUser)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.DeleteRewardTier(rewardTier)
+}
+
+// SubmitCampaign sends a draft campaign to the admins for review. The
+// campaign needs an end date in the future before it can be submitted.
+func (s *service) SubmitCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error) {
+	campaign, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		return campaign, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != currentUser.ID {
+		return campaign, errors.New("You do not have authorization for change the campaign!")
+	}
+
+	if campaign.EndsAt == nil || !campaign.EndsAt.After(time.Now()) {
+		return campaign, errors.New("The campaign needs an end date in the future before it can be submitted!")
+	}
+
+	return s.transition(campaign, StatusPendingReview)
+}
+
+// ApproveCampaign puts a campaign under review live. Campaigns without a start
+// date start right away.
+func (s *service) ApproveCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error) {
+	campaign, err := s.findReviewedCampaign(input, currentUser)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.EndsAt == nil || !campaign.EndsAt.After(time.Now()) {
+		return campaign, errors.New("The campaign has already passed its end date!")
+	}
+
+	updatedCampaign, err := s.transition(campaign, StatusLive)
+	if err != nil {
+		return updatedCampaign, err
+	}
+
+	if updatedCampaign.StartsAt == nil {
+		now := time.Now()
+
+		err = s.repository.SetStartsAt(updatedCampaign, now)
+		if err != nil {
+			return updatedCampaign, err
+		}
+		updatedCampaign.StartsAt = &now
+	}
+
+	return updatedCampaign, nil
+}
+
+// RejectCampaign sends a campaign under review back to its owner as a draft.
+func (s *service) RejectCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error) {
+	campaign, err := s.findReviewedCampaign(input, currentUser)
+	if err != nil {
+		return campaign, err
+	}
+
+	return s.transition(campaign, StatusDraft)
+}
+
+// CloseCampaign lets the owner close a finished campaign. Failed
+// all-or-nothing campaigns are closed by the deadline job once every donation
+// has been refunded.
+func (s *service) CloseCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error) {
+	campaign, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		return campaign, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != currentUser.ID && currentUser.Role != user.RoleAdmin {
+		return campaign, errors.New("You do not have authorization for change the campaign!")
+	}
+
+	if campaign.Status == StatusFailed && campaign.FundingModel == FundingAllOrNothing {
+		return campaign, errors.New("The campaign is closed automatically once its donations have been refunded!")
+	}
+
+	return s.transition(campaign, StatusClosed)
+}
+
+func (s *service) GetCampaignsByStatus(status string) ([]Campaign, error) {
+	if status == "" {
+		status = StatusPendingReview
+	}
+
+	campaigns, err := s.repository.FindByStatus(status)
+	if err != nil {
+		return campaigns, err
+	}
+
+	return campaigns, nil
+}
+
+// FinishEndedCampaigns marks every live campaign past its end date as
+// successful when it reached its goal and as failed otherwise. It returns how
+// many campaigns were finished.
+func (s *service) FinishEndedCampaigns(ctx context.Context) (int, error) {
+	campaigns, err := s.repository.FindLiveEndedBefore(time.Now(), finishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for _, campaign := range campaigns {
+		if ctx.Err() != nil {
+			return finished, ctx.Err()
+		}
+
+		toStatus := StatusFailed
+		if campaign.CurrentAmount >= campaign.GoalAmount {
+			toStatus = StatusSuccessful
+		}
+
+		updated, err := s.repository.UpdateStatus(campaign, toStatus)
+		if err != nil {
+			return finished, err
+		}
+
+		if updated {
+			finished++
+		}
+	}
+
+	return finished, nil
+}
+
+// GetCampaignsAwaitingRefund returns the failed all-or-nothing campaigns whose
+// donations still have to be refunded.
+func (s *service) GetCampaignsAwaitingRefund() ([]Campaign, error) {
+	campaigns, err := s.repository.FindFailedByFundingModel(FundingAllOrNothing)
+	if err != nil {
+		return campaigns, err
+	}
+
+	return campaigns, nil
+}
+
+// MarkCampaignRefunded closes a failed campaign once all of its donations have
+// been refunded.
+func (s *service) MarkCampaignRefunded(campaign Campaign) error {
+	if campaign.Status != StatusFailed {
+		return ErrInvalidTransition
+	}
+
+	_, err := s.repository.UpdateStatus(campaign, StatusClosed)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetCategories returns the category hierarchy with the number of live
+// campaigns in every category.
+func (s *service) GetCategories() ([]CategoryNode, error) {
+	categories, err := s.repository.FindCategories()
+	if err != nil {
+		return []CategoryNode{}, err
+	}
+
+	counts, err := s.repository.CountLiveByCategory()
+	if err != nil {
+		return []CategoryNode{}, err
+	}
+
+	return buildCategoryTree(categories, counts, 0), nil
+}
+
+func (s *service) GetCategory(input GetCategoryInput) (CategoryNode, error) {
+	categories, err := s.GetCategories()
+	if err != nil {
+		return CategoryNode{}, err
+	}
+
+	node, found := findCategoryNode(categories, input.ID)
+	if !found {
+		return node, errors.New("No category found with that ID")
+	}
+
+	return node, nil
+}
+
+func (s *service) CreateCategory(input CategoryInput) (Category, error) {
+	if input.User.Role != user.RoleAdmin {
+		return Category{}, errors.New("You do not have authorization to manage categories!")
+	}
+
+	category := Category{}
+	category, err := s.applyCategoryInput(category, input)
+	if err != nil {
+		return category, err
+	}
+
+	newCategory, err := s.repository.SaveCategory(category)
+	if err != nil {
+		return newCategory, err
+	}
+
+	return newCategory, nil
+}
+
+func (s *service) UpdateCategory(inputURI GetCategoryInput, input CategoryInput) (Category, error) {
+	category, err := s.findManagedCategory(inputURI, input.User)
+	if err != nil {
+		return category, err
+	}
+
+	category, err = s.applyCategoryInput(category, input)
+	if err != nil {
+		return category, err
+	}
+
+	updatedCategory, err := s.repository.UpdateCategory(category)
+	if err != nil {
+		return updatedCategory, err
+	}
+
+	return updatedCategory, nil
+}
+
+func (s *service) DeleteCategory(inputURI GetCategoryInput, currentUser user.User) error {
+	category, err := s.findManagedCategory(inputURI, currentUser)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.DeleteCategory(category)
+}
+
+func (s *service) findManagedCategory(input GetCategoryInput, currentUser user.User) (Category, error) {
+	if currentUser.Role != user.RoleAdmin {
+		return Category{}, errors.New("You do not have authorization to manage categories!")
+	}
+
+	category, err := s.repository.FindCategoryByID(input.ID)
+	if err != nil {
+		return category, err
+	}
+
+	if category.ID == 0 {
+		return category, errors.New("No category found with that ID")
+	}
+
+	return category, nil
+}
+
+// applyCategoryInput copies the input onto the category, checking that the
+// slug is free and that the parent exists and is not the category itself or
+// one of its subcategories.
+func (s *service) applyCategoryInput(category Category, input CategoryInput) (Category, error) {
+	categorySlug := slug.Make(input.Name)
+
+	existing, err := s.repository.FindCategoryBySlug(categorySlug)
+	if err != nil {
+		return category, err
+	}
+
+	if existing.ID != 0 && existing.ID != category.ID {
+		return category, errors.New("A category with that name already exists!")
+	}
+
+	if input.ParentID != 0 {
+		categories, err := s.repository.FindCategories()
+		if err != nil {
+			return category, err
+		}
+
+		if _, found := findCategory(categories, input.ParentID); !found {
+			return category, errors.New("No parent category found with that ID")
+		}
+
+		if category.ID != 0 && slices.Contains(descendantIDs(categories, category.ID), input.ParentID) {
+			return category, errors.New("A category cannot be moved under itself!")
+		}
+	}
+
+	category.Name = input.Name
+	category.Slug = categorySlug
+	category.ParentID = input.ParentID
+
+	return category, nil
+}
+
+func (s *service) validateCategory(categoryID int) error {
+	if categoryID == 0 {
+		return nil
+	}
+
+	category, err := s.repository.FindCategoryByID(categoryID)
+	if err != nil {
+		return err
+	}
+
+	if category.ID == 0 {
+		return errors.New("No category found with that ID")
+	}
+
+	return nil
+}
+
+// GetCampaignUpdates lists the updates of a campaign, newest first. It also
+// reports whether the user may read the updates for backers only, which is
+// the case for the owner, admins and backers of the campaign.
+func (s *service) GetCampaignUpdates(input GetCampaignDetailInput, currentUser user.User) ([]CampaignUpdate, bool, error) {
+	campaign, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return []CampaignUpdate{}, false, err
+	}
+
+	if campaign.ID == 0 || !campaign.VisibleTo(currentUser) {
+		return []CampaignUpdate{}, false, errors.New("No campaign found with that ID")
+	}
+
+	canViewBackersOnly, err := s.canViewBackersOnly(campaign, currentUser)
+	if err != nil {
+		return []CampaignUpdate{}, false, err
+	}
+
+	updates, err := s.repository.FindCampaignUpdatesByCampaignID(campaign.ID)
+	if err != nil {
+		return updates, false, err
+	}
+
+	return updates, canViewBackersOnly, nil
+}
+
+func (s *service) GetCampaignUpdate(input GetCampaignUpdateInput, currentUser user.User) (CampaignUpdate, bool, error) {
+	update, err := s.repository.FindCampaignUpdateByID(input.UpdateID)
+	if err != nil {
+		return update, false, err
+	}
+
+	if update.ID == 0 || update.CampaignID != input.ID {
+		return update, false, errors.New("No campaign update found with that ID")
+	}
+
+	campaign, err := s.repository.FindByID(update.CampaignID)
+	if err != nil {
+		return update, false, err
+	}
+
+	if !campaign.VisibleTo(currentUser) {
+		return CampaignUpdate{}, false, errors.New("No campaign update found with that ID")
+	}
+
+	canViewBackersOnly, err := s.canViewBackersOnly(campaign, currentUser)
+	if err != nil {
+		return update, false, err
+	}
+
+	return update, canViewBackersOnly, nil
+}
+
+// CreateCampaignUpdate publishes an update on the campaign and queues an
+// email about it to every backer. SendUpdateNotifications sends the emails,
+// so failing to reach a backer does not fail the update.
+func (s *service) CreateCampaignUpdate(inputURI GetCampaignDetailInput, input CampaignUpdateInput) (CampaignUpdate, error) {
+	campaign, err := s.repository.FindByID(inputURI.ID)
+	if err != nil {
+		return CampaignUpdate{}, err
+	}
+
+	if campaign.ID == 0 {
+		return CampaignUpdate{}, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != input.User.ID {
+		return CampaignUpdate{}, errors.New("You do not have authorization for change the campaign!")
+	}
+
+	update := CampaignUpdate{
+		CampaignID: campaign.ID,
+		UserID:     input.User.ID,
+		Title:      input.Title,
+		Body:       input.Body,
+		Visibility: input.Visibility,
+	}
+
+	if update.Visibility == "" {
+		update.Visibility = VisibilityPublic
+	}
+
+	recipients, err := s.backerDirectory.GetBackerEmails(campaign.ID)
+	if err != nil {
+		return CampaignUpdate{}, err
+	}
+
+	newUpdate, err := s.repository.SaveCampaignUpdate(update, recipients)
+	if err != nil {
+		return newUpdate, err
+	}
+
+	return newUpdate, nil
+}
+
+// SendUpdateNotifications sends the queued update emails that are due, a few
+// at a time. Emails that fail are tried again later, up to
+// maxNotificationAttempts times. It returns how many emails were sent.
+func (s *service) SendUpdateNotifications(ctx context.Context) (int, error) {
+	notifications, err := s.repository.FindNotificationsDue(time.Now(), notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type result struct {
+		sent bool
+		err  error
+	}
+
+	queue := make(chan CampaignUpdateNotification)
+	results := make(chan result, len(notifications))
+
+	var senders sync.WaitGroup
+	for i := 0; i < notificationSenders; i++ {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+			for notification := range queue {
+				sent, err := s.sendNotification(notification)
+				results <- result{sent: sent, err: err}
+			}
+		}()
+	}
+
+	for _, notification := range notifications {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- notification
+	}
+	close(queue)
+	senders.Wait()
+	close(results)
+
+	sent := 0
+	for outcome := range results {
+		if outcome.err != nil {
+			err = outcome.err
+		}
+		if outcome.sent {
+			sent++
+		}
+	}
+
+	if err != nil {
+		return sent, err
+	}
+
+	return sent, ctx.Err()
+}
+
+// UpdateCampaignUpdate edits an update. Backers are only notified of new
+// updates, not of edits.
+func (s *service) UpdateCampaignUpdate(inputURI GetCampaignUpdateInput, input CampaignUpdateInput) (CampaignUpdate, error) {
+	update, err := s.findManagedCampaignUpdate(inputURI, input.User)
+	if err != nil {
+		return update, err
+	}
+
+	update.Title = input.Title
+	update.Body = input.Body
+	if input.Visibility != "" {
+		update.Visibility = input.Visibility
+	}
+
+	updatedUpdate, err := s.repository.UpdateCampaignUpdate(update)
+	if err != nil {
+		return updatedUpdate, err
+	}
+
+	return updatedUpdate, nil
+}
+
+func (s *service) DeleteCampaignUpdate(inputURI GetCampaignUpdateInput, currentUser user.User) error {
+	update, err := s.findManagedCampaignUpdate(inputURI, currentUser)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.DeleteCampaignUpdate(update)
+}
+
+func (s *service) SaveCampaignUpdateImage(inputURI GetCampaignUpdateInput, currentUser user.User, filePath string) (CampaignUpdateImage, error) {
+	update, err := s.findManagedCampaignUpdate(inputURI, currentUser)
+	if err != nil {
+		return CampaignUpdateImage{}, err
+	}
+
+	image := CampaignUpdateImage{
+		CampaignUpdateID: update.ID,
+		FileName:         filePath,
+	}
+
+	newImage, err := s.repository.CreateCampaignUpdateImage(image)
+	if err != nil {
+		return newImage, err
+	}
+
+	return newImage, nil
+}
+
+func (s *service) findManagedCampaignUpdate(input GetCampaignUpdateInput, currentUser user.User) (CampaignUpdate, error) {
+	update, err := s.repository.FindCampaignUpdateByID(input.UpdateID)
+	if err != nil {
+		return update, err
+	}
+
+	if update.ID == 0 || update.CampaignID != input.ID {
+		return update, errors.New("No campaign update found with that ID")
+	}
+
+	campaign, err := s.repository.FindByID(update.CampaignID)
+	if err != nil {
+		return update, err
+	}
+
+	if campaign.UserID != currentUser.ID {
+		return update, errors.New("You do not have authorization for change the campaign!")
+	}
+
+	return update, nil
+}
+
+func (s *service) canViewBackersOnly(campaign Campaign, currentUser user.User) (bool, error) {
+	if currentUser.ID == 0 {
+		return false, nil
+	}
+
+	if campaign.UserID == currentUser.ID || currentUser.Role == user.RoleAdmin {
+		return true, nil
+	}
+
+	return s.backerDirectory.IsBacker(campaign.ID, currentUser.ID)
+}
+
+// uniqueSlug returns the slug for a campaign name, numbered when another
+// campaign uses or used it already. Slugs a campaign used itself before are
+// given back to it. campaignID is zero for new campaigns.
+func (s *service) uniqueSlug(name string, campaignID int) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "campaign"
+	}
+
+	candidate := base
+	for number := 2; ; number++ {
+		ownerID, err := s.repository.FindSlugOwner(candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if ownerID == 0 || ownerID == campaignID {
+			return candidate, nil
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, number)
+	}
+}
+
+// sendNotification emails one queued update, records the outcome and reports
+// whether the email went out. It only returns an error when the outcome could
+// not be recorded.
+func (s *service) sendNotification(notification CampaignUpdateNotification) (bool, error) {
+	err := s.backerDirectory.NotifyBacker(notification.Email, notification.Campaign, notification.CampaignUpdate)
+	if err == nil {
+		return true, s.repository.MarkNotificationSent(notification)
+	}
+
+	notification.LastError = err.Error()
+	if notification.Attempts+1 >= maxNotificationAttempts {
+		log.Printf("giving up on email of campaign update %d: %v", notification.CampaignUpdateID, err)
+		return false, s.repository.FailNotification(notification)
+	}
+
+	delay := notificationRetryDelay << notification.Attempts
+	return false, s.repository.DeferNotification(notification, time.Now().Add(delay))
+}
+
+// saveWithSlug saves a campaign under the slug of its name. The unique
+// indexes on the slugs reject a slug another campaign took in the meantime,
+// in which case the next free one is tried.
+func (s *service) saveWithSlug(campaign Campaign, save func(Campaign) (Campaign, error)) (Campaign, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		campaignSlug, err := s.uniqueSlug(campaign.Name, campaign.ID)
+		if err != nil {
+			return campaign, err
+		}
+		campaign.Slug = campaignSlug
+
+		savedCampaign, err := save(campaign)
+		if errors.Is(err, ErrDuplicateSlug) {
+			continue
+		}
+
+		return savedCampaign, err
+	}
+
+	return campaign, errors.New("Failed to generate a unique campaign slug!")
+}
+
+// syncSearchIndex indexes a public campaign and removes any other from the
+// search index. The index only serves search, so failing to update it must
+// not fail the change that triggered it.
+func (s *service) syncSearchIndex(campaign Campaign) {
+	var err error
+
+	if slices.Contains(publicStatuses, campaign.Status) {
+		err = s.searchIndex.Index(NewSearchDocument(campaign))
+	} else {
+		err = s.searchIndex.Remove(campaign.ID)
+	}
+
+	if err != nil {
+		log.Printf("failed to update search index for campaign %d: %v", campaign.ID, err)
+	}
+}
+
+func (s *service) findReviewedCampaign(input GetCampaignDetailInput, currentUser user.User) (Campaign, error) {
+	if currentUser.Role != user.RoleAdmin {
+		return Campaign{}, errors.New("You do not have authorization to review campaigns!")
+	}
+
+	campaign, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		return campaign, errors.New("No campaign found with that ID")
+	}
+
+	return campaign, nil
+}
+
+// transition moves the campaign to toStatus if the lifecycle allows it and
+// nobody else moved it in the meantime.
+func (s *service) transition(campaign Campaign, toStatus string) (Campaign, error) {
+	if !CanTransition(campaign.Status, toStatus) {
+		return campaign, ErrInvalidTransition
+	}
+
+	updated, err := s.repository.UpdateStatus(campaign, toStatus)
+	if err != nil {
+		return campaign, err
+	}
+
+	if !updated {
+		return campaign, ErrInvalidTransition
+	}
+
+	campaign.Status = toStatus
+	s.syncSearchIndex(campaign)
+
+	return campaign, nil
+}
+
+// findManagedRewardTier returns a tier of the campaign in the URI if the user
+// owns that campaign.
+func (s *service) findManagedRewardTier(inputURI GetRewardTierInput, currentUser user.User) (RewardTier, error) {
+	campaign, err := s.repository.FindByID(inputURI.ID)
+	if err != nil {
+		return RewardTier{}, err
+	}
+
+	if campaign.ID == 0 {
+		return RewardTier{}, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != currentUser.ID {
+		return RewardTier{}, errors.New("You do not have authorization for change the campaign!")
+	}
+
+	rewardTier, err := s.repository.FindRewardTierByID(inputURI.TierID)
+	if err != nil {
+		return rewardTier, err
+	}
+
+	if rewardTier.ID == 0 || rewardTier.CampaignID != campaign.ID {
+		return rewardTier, errors.New("No reward tier found with that ID")
+	}
+
+	return rewardTier, nil
+}
+
+func applyRewardTierInput(rewardTier RewardTier, input RewardTierInput) (RewardTier, error) {
+	rewardTier.Title = input.Title
+	rewardTier.Description = input.Description
+	rewardTier.MinimumPledge = input.MinimumPledge
+	rewardTier.LimitedQuantity = input.LimitedQuantity
+	rewardTier.ShippingRequired = input.ShippingRequired
+	rewardTier.EstimatedDelivery = nil
+
+	if input.EstimatedDelivery != "" {
+		estimatedDelivery, err := time.Parse("2006-01", input.EstimatedDelivery)
+		if err != nil {
+			return rewardTier, err
+		}
+		rewardTier.EstimatedDelivery = &estimatedDelivery
+	}
+
+	return rewardTier, nil
+}
+
+// validateSchedule checks that a campaign ends after it starts.
+func validateSchedule(startsAt *time.Time, endsAt *time.Time) error {
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return errors.New("The campaign must end after it starts!")
+	}
+
+	return nil
+}
+
+func parseListInput(input GetCampaignsInput) (CampaignFilter, PageQuery, error) {
+	filter := CampaignFilter{
+		UserID:           input.UserID,
+		Status:           input.Status,
+		Tags:             normalizeTags(input.Tags),
+		MinGoal:          input.MinGoal,
+		MaxGoal:          input.MaxGoal,
+		MinFundedPercent: input.MinFunded,
+		MaxFundedPercent: input.MaxFunded,
+	}
+	page := PageQuery{Sort: input.Sort, Limit: input.Limit}
+
+	if page.Sort == "" {
+		page.Sort = SortNewest
+	}
+
+	if page.Limit == 0 {
+		page.Limit = defaultPageLimit
+	}
+
+	if filter.MaxGoal != 0 && filter.MinGoal > filter.MaxGoal {
+		return filter, page, errors.New("The minimum goal must not be above the maximum goal!")
+	}
+
+	if filter.MaxFundedPercent != 0 && filter.MinFundedPercent > filter.MaxFundedPercent {
+		return filter, page, errors.New("The minimum funded percentage must not be above the maximum funded percentage!")
+	}
+
+	// Only live campaigns are still ending; the others have ended already.
+	if page.Sort == SortEndingSoon {
+		if filter.Status != "" && filter.Status != StatusLive {
+			return filter, page, errors.New("Only live campaigns can be sorted by ending soon!")
+		}
+
+		filter.Status = StatusLive
+	}
+
+	if input.Page != 0 && input.Cursor != "" {
+		return filter, page, errors.New("Use either a page number or a cursor, not both!")
+	}
+
+	if input.Page != 0 {
+		page.Offset = (input.Page - 1) * page.Limit
+	}
+
+	if input.Cursor != "" {
+		cursor, err := decodeCursor(page.Sort, input.Cursor)
+		if err != nil {
+			return filter, page, err
+		}
+
+		page.Cursor = cursor
+	}
+
+	return filter, page, nil
+}
+
+// paginate cuts the extra campaign the repository loaded to look ahead off
+// the page and points the next cursor at the last campaign kept.
+func paginate(campaigns []Campaign, query PageQuery) ([]Campaign, Page) {
+	page := Page{Limit: query.Limit}
+
+	if query.Limit > 0 && len(campaigns) > query.Limit {
+		campaigns = campaigns[:query.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(query.Sort, campaigns[len(campaigns)-1])
+	}
+
+	return campaigns, page
+}
+
+// normalizeTags lowercases and trims tags, splits comma-separated ones and
+// drops empty and duplicate tags.
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+
+	for _, tag := range tags {
+		for _, name := range strings.Split(tag, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && !slices.Contains(normalized, name) {
+				normalized = append(normalized, name)
+			}
+		}
+	}
+
+	return normalized
+}
+
+// descendantIDs returns the ID of a category and of all its subcategories.
+func descendantIDs(categories []Category, ID int) []int {
+	IDs := []int{ID}
+
+	for i := 0; i < len(IDs); i++ {
+		for _, category := range categories {
+			if category.ParentID == IDs[i] {
+				IDs = append(IDs, category.ID)
+			}
+		}
+	}
+
+	return IDs
+}
+
+func findCategory(categories []Category, ID int) (Category, bool) {
+	for _, category := range categories {
+		if category.ID == ID {
+			return category, true
+		}
+	}
+
+	return Category{}, false
+}
+
+// buildCategoryTree returns the subcategories of parentID with their own
+// subcategories, adding the live campaign counts of subcategories to their
+// parents.
+func buildCategoryTree(categories []Category, counts map[int]int, parentID int) []CategoryNode {
+	nodes := []CategoryNode{}
+
+	for _, category := range categories {
+		if category.ParentID != parentID {
+			continue
+		}
+
+		node := CategoryNode{
+			Category:          category,
+			LiveCampaignCount: counts[category.ID],
+			Children:          buildCategoryTree(categories, counts, category.ID),
+		}
+
+		for _, child := range node.Children {
+			node.LiveCampaignCount += child.LiveCampaignCount
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+func findCategoryNode(nodes []CategoryNode, ID int) (CategoryNode, bool) {
+	for _, node := range nodes {
+		if node.Category.ID == ID {
+			return node, true
+		}
+
+		if found, ok := findCategoryNode(node.Children, ID); ok {
+			return found, true
+		}
+	}
+
+	return CategoryNode{}, false
+}
@@ -0,0 +1,93 @@
+package campaign
+
+import (
+	"cfa-backend/user"
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	StatusDraft         = "draft"
+	StatusPendingReview = "pending_review"
+	StatusLive          = "live"
+	StatusSuccessful    = "successful"
+	StatusFailed        = "failed"
+	StatusClosed        = "closed"
+)
+
+const (
+	// FundingAllOrNothing campaigns only keep their donations when the goal is
+	// reached by the deadline; otherwise every donation is refunded.
+	FundingAllOrNothing = "all_or_nothing"
+	// FundingKeepWhatYouRaise campaigns keep their donations either way.
+	FundingKeepWhatYouRaise = "keep_what_you_raise"
+)
+
+var (
+	ErrInvalidTransition     = errors.New("Campaign status transition is not allowed!")
+	ErrNotAcceptingDonations = errors.New("The campaign is not accepting donations!")
+)
+
+// transitions lists, for every status, the statuses a campaign may move to
+// next. A rejected review sends the campaign back to draft.
+var transitions = map[string][]string{
+	StatusDraft:         {StatusPendingReview},
+	StatusPendingReview: {StatusLive, StatusDraft},
+	StatusLive:          {StatusSuccessful, StatusFailed},
+	StatusSuccessful:    {StatusClosed},
+	StatusFailed:        {StatusClosed},
+}
+
+// publicStatuses are the statuses of campaigns shown in public listings.
+var publicStatuses = []string{StatusLive, StatusSuccessful, StatusFailed, StatusClosed}
+
+func CanTransition(from string, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AcceptsDonations reports whether the campaign is live and inside its
+// start and end dates at the given time.
+func (c Campaign) AcceptsDonations(now time.Time) bool {
+	if c.Status != StatusLive {
+		return false
+	}
+
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return false
+	}
+
+	return c.EndsAt == nil || now.Before(*c.EndsAt)
+}
+
+// VisibleTo reports whether the campaign may be shown to currentUser. Drafts
+// and campaigns in review are only shown to their owner and admins; the zero
+// user stands for an anonymous visitor.
+func (c Campaign) VisibleTo(currentUser user.User) bool {
+	if slices.Contains(publicStatuses, c.Status) {
+		return true
+	}
+
+	if currentUser.ID == 0 {
+		return false
+	}
+
+	return c.UserID == currentUser.ID || currentUser.Role == user.RoleAdmin
+}
+
+// FundsReleasable reports whether the donations of the campaign may be paid
+// out to its creator. All-or-nothing campaigns hold them until the goal has
+// been reached at the deadline.
+func (c Campaign) FundsReleasable() bool {
+	if c.FundingModel != FundingAllOrNothing {
+		return true
+	}
+
+	return c.Status == StatusSuccessful || (c.Status == StatusClosed && c.CurrentAmount >= c.GoalAmount)
+}
@@ -0,0 +1,41 @@
+package campaign
+
+import (
+	"cfa-backend/user"
+	"testing"
+)
+
+func TestCampaignVisibleTo(t *testing.T) {
+	owner := user.User{ID: 1}
+	admin := user.User{ID: 2, Role: user.RoleAdmin}
+	visitor := user.User{ID: 3}
+	anonymous := user.User{}
+
+	tests := []struct {
+		status string
+		user   user.User
+		want   bool
+	}{
+		{StatusLive, anonymous, true},
+		{StatusSuccessful, visitor, true},
+		{StatusFailed, anonymous, true},
+		{StatusClosed, visitor, true},
+		{StatusDraft, anonymous, false},
+		{StatusDraft, visitor, false},
+		{StatusDraft, owner, true},
+		{StatusDraft, admin, true},
+		{StatusPendingReview, anonymous, false},
+		{StatusPendingReview, visitor, false},
+		{StatusPendingReview, owner, true},
+		{StatusPendingReview, admin, true},
+	}
+
+	for _, test := range tests {
+		campaign := Campaign{ID: 10, UserID: owner.ID, Status: test.status}
+
+		got := campaign.VisibleTo(test.user)
+		if got != test.want {
+			t.Errorf("%s campaign VisibleTo(%+v) = %t, want %t", test.status, test.user, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package fee
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+)
+
+// Rule describes one fee: a percentage of the gross amount plus a flat
+// amount, kept between Min and Max. A Max of zero means the fee is not capped.
+type Rule struct {
+	Percentage float64 `json:"percentage"`
+	Flat       int     `json:"flat"`
+	Min        int     `json:"min"`
+	Max        int     `json:"max"`
+}
+
+// Schedule holds every fee rule charged on donations. The processing fee can
+// be overridden per payment method, and campaigns listed in ExemptCampaigns
+// pay no platform fee.
+type Schedule struct {
+	Platform        Rule            `json:"platform"`
+	Processing      Rule            `json:"processing"`
+	PaymentMethods  map[string]Rule `json:"payment_methods"`
+	ExemptCampaigns []int           `json:"exempt_campaigns"`
+}
+
+type Breakdown struct {
+	Gross         int
+	PlatformFee   int
+	ProcessingFee int
+	Net           int
+}
+
+// DefaultSchedule is used when no schedule file is configured.
+func DefaultSchedule() Schedule {
+	return Schedule{
+		Platform:   Rule{Percentage: 5},
+		Processing: Rule{Flat: 4000},
+		PaymentMethods: map[string]Rule{
+			"bank_transfer": {Flat: 4000},
+			"gopay":         {Percentage: 2},
+			"qris":          {Percentage: 0.7},
+			"credit_card":   {Percentage: 2.9, Flat: 2000},
+		},
+	}
+}
+
+// LoadSchedule reads a schedule from a JSON file.
+func LoadSchedule(path string) (Schedule, error) {
+	var schedule Schedule
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return schedule, err
+	}
+
+	err = json.Unmarshal(content, &schedule)
+	if err != nil {
+		return schedule, err
+	}
+
+	return schedule, nil
+}
+
+// Apply returns the fee the rule charges on amount, rounded to whole rupiah.
+func (r Rule) Apply(amount int) int {
+	fee := int(math.Round(float64(amount)*r.Percentage/100)) + r.Flat
+
+	if fee < r.Min {
+		fee = r.Min
+	}
+
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+
+	return fee
+}
+
+// Calculate splits a gross donation amount into fees and the net amount that
+// goes to the campaign. Fees never exceed the gross amount.
+func (s Schedule) Calculate(amount int, paymentMethod string, campaignID int) Breakdown {
+	breakdown := Breakdown{Gross: amount}
+
+	if !s.isExempt(campaignID) {
+		breakdown.PlatformFee = s.Platform.Apply(amount)
+	}
+
+	processing, ok := s.PaymentMethods[paymentMethod]
+	if !ok {
+		processing = s.Processing
+	}
+	breakdown.ProcessingFee = processing.Apply(amount)
+
+	if breakdown.PlatformFee > amount {
+		breakdown.PlatformFee = amount
+	}
+
+	if breakdown.PlatformFee+breakdown.ProcessingFee > amount {
+		breakdown.ProcessingFee = amount - breakdown.PlatformFee
+	}
+
+	breakdown.Net = amount - breakdown.PlatformFee - breakdown.ProcessingFee
+
+	return breakdown
+}
+
+func (s Schedule) isExempt(campaignID int) bool {
+	for _, exempt := range s.ExemptCampaigns {
+		if exempt == campaignID {
+			return true
+		}
+	}
+
+	return false
+}
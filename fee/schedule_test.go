@@ -0,0 +1,67 @@
+package fee
+
+import "testing"
+
+func TestRuleApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   Rule
+		amount int
+		want   int
+	}{
+		{"percentage", Rule{Percentage: 5}, 100000, 5000},
+		{"percentage and flat", Rule{Percentage: 2.9, Flat: 2000}, 100000, 4900},
+		{"rounds down", Rule{Percentage: 0.7}, 10050, 70},
+		{"rounds up", Rule{Percentage: 0.7}, 10072, 71},
+		{"rounds half up", Rule{Percentage: 5}, 10, 1},
+		{"raised to min", Rule{Percentage: 1, Min: 1000}, 50000, 1000},
+		{"above min", Rule{Percentage: 1, Min: 1000}, 500000, 5000},
+		{"capped at max", Rule{Percentage: 5, Max: 25000}, 1000000, 25000},
+		{"no max", Rule{Percentage: 5}, 1000000, 50000},
+		{"flat only", Rule{Flat: 4000}, 10000, 4000},
+		{"no fee", Rule{}, 100000, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rule.Apply(test.amount)
+			if got != test.want {
+				t.Errorf("%+v.Apply(%d) = %d, want %d", test.rule, test.amount, got, test.want)
+			}
+		})
+	}
+}
+
+func TestScheduleCalculate(t *testing.T) {
+	schedule := DefaultSchedule()
+	schedule.ExemptCampaigns = []int{7}
+
+	tests := []struct {
+		name          string
+		schedule      Schedule
+		amount        int
+		paymentMethod string
+		campaignID    int
+		want          Breakdown
+	}{
+		{"default processing", schedule, 100000, "", 1, Breakdown{Gross: 100000, PlatformFee: 5000, ProcessingFee: 4000, Net: 91000}},
+		{"unknown payment method", schedule, 100000, "cstore", 1, Breakdown{Gross: 100000, PlatformFee: 5000, ProcessingFee: 4000, Net: 91000}},
+		{"percentage override", schedule, 100000, "gopay", 1, Breakdown{Gross: 100000, PlatformFee: 5000, ProcessingFee: 2000, Net: 93000}},
+		{"percentage and flat override", schedule, 100000, "credit_card", 1, Breakdown{Gross: 100000, PlatformFee: 5000, ProcessingFee: 4900, Net: 90100}},
+		{"rounded override", schedule, 10050, "qris", 1, Breakdown{Gross: 10050, PlatformFee: 503, ProcessingFee: 70, Net: 9477}},
+		{"exempt campaign", schedule, 100000, "bank_transfer", 7, Breakdown{Gross: 100000, PlatformFee: 0, ProcessingFee: 4000, Net: 96000}},
+		{"processing fee cut to the gross", schedule, 4000, "bank_transfer", 1, Breakdown{Gross: 4000, PlatformFee: 200, ProcessingFee: 3800, Net: 0}},
+		{"platform fee cut to the gross", Schedule{Platform: Rule{Flat: 5000}, Processing: Rule{Flat: 1000}}, 3000, "", 1, Breakdown{Gross: 3000, PlatformFee: 3000, ProcessingFee: 0, Net: 0}},
+		{"capped fees", Schedule{Platform: Rule{Percentage: 5, Max: 25000}, Processing: Rule{Percentage: 1, Min: 2500}}, 1000000, "", 1, Breakdown{Gross: 1000000, PlatformFee: 25000, ProcessingFee: 10000, Net: 965000}},
+		{"minimum fees", Schedule{Platform: Rule{Percentage: 5, Max: 25000}, Processing: Rule{Percentage: 1, Min: 2500}}, 10000, "", 1, Breakdown{Gross: 10000, PlatformFee: 500, ProcessingFee: 2500, Net: 7000}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.schedule.Calculate(test.amount, test.paymentMethod, test.campaignID)
+			if got != test.want {
+				t.Errorf("Calculate(%d, %q, %d) = %+v, want %+v", test.amount, test.paymentMethod, test.campaignID, got, test.want)
+			}
+		})
+	}
+}
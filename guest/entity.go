@@ -0,0 +1,16 @@
+package guest
+
+import "time"
+
+// Guest is the identity of a backer who donated without an account. Once an
+// account registers with the same email, UserID points at it and the guest's
+// transactions are moved over to that user.
+type Guest struct {
+	ID        int
+	Name      string
+	Email     string
+	UserID    int
+	MergedAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,60 @@
+package guest
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	FindByID(ID int) (Guest, error)
+	FindByEmail(email string) (Guest, error)
+	Save(guest Guest) (Guest, error)
+	Update(guest Guest) (Guest, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindByID(ID int) (Guest, error) {
+	var guest Guest
+	err := r.db.Where("id = ?", ID).Find(&guest).Error
+
+	if err != nil {
+		return guest, err
+	}
+
+	return guest, nil
+}
+
+func (r *repository) FindByEmail(email string) (Guest, error) {
+	var guest Guest
+	err := r.db.Where("email = ?", email).Find(&guest).Error
+
+	if err != nil {
+		return guest, err
+	}
+
+	return guest, nil
+}
+
+func (r *repository) Save(guest Guest) (Guest, error) {
+	err := r.db.Create(&guest).Error
+
+	if err != nil {
+		return guest, err
+	}
+
+	return guest, nil
+}
+
+func (r *repository) Update(guest Guest) (Guest, error) {
+	err := r.db.Save(&guest).Error
+
+	if err != nil {
+		return guest, err
+	}
+
+	return guest, nil
+}
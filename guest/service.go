@@ -0,0 +1,266 @@
+package guest
+
+import (
+	"cfa-backend/mailer"
+	"cfa-backend/user"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ReceiptLinkTTL is how long the magic link to a guest receipt stays valid.
+const ReceiptLinkTTL = 30 * 24 * time.Hour
+
+// MergeLinkTTL is how long the link that confirms a guest merge stays valid.
+const MergeLinkTTL = 7 * 24 * time.Hour
+
+var (
+	ErrEmailRegistered     = errors.New("An account with this email already exists, please log in to donate!")
+	ErrInvalidReceiptToken = errors.New("Invalid or expired receipt link!")
+	ErrInvalidMergeToken   = errors.New("Invalid or expired confirmation link!")
+)
+
+type Service interface {
+	FindOrCreateGuest(name string, email string) (Guest, error)
+	GetGuestByID(ID int) (Guest, error)
+	GetGuestByEmail(email string) (Guest, error)
+	MarkAsMerged(guest Guest, userID int) (Guest, error)
+	GenerateReceiptToken(code string) (string, error)
+	ValidateReceiptToken(code string, token string) error
+	SendReceiptLink(guest Guest, code string, paymentURL string) error
+	SendMergeLink(guest Guest, newUser user.User) error
+	VerifyMergeToken(token string) (Guest, user.User, error)
+}
+
+type service struct {
+	repository     Repository
+	userRepository user.Repository
+	mailer         mailer.Mailer
+	appURL         string
+	secret         []byte
+}
+
+func NewService(repository Repository, userRepository user.Repository, mailer mailer.Mailer, appURL string, secret string) *service {
+	return &service{
+		repository:     repository,
+		userRepository: userRepository,
+		mailer:         mailer,
+		appURL:         appURL,
+		secret:         []byte(secret),
+	}
+}
+
+// FindOrCreateGuest returns the guest identity of an email, creating it on the
+// first donation. Emails that belong to a registered account are refused so
+// that those donations end up on the account.
+func (s *service) FindOrCreateGuest(name string, email string) (Guest, error) {
+	email = normalizeEmail(email)
+
+	registeredUser, err := s.userRepository.FindByEmail(email)
+	if err != nil {
+		return Guest{}, err
+	}
+
+	if registeredUser.ID != 0 {
+		return Guest{}, ErrEmailRegistered
+	}
+
+	guest, err := s.repository.FindByEmail(email)
+	if err != nil {
+		return guest, err
+	}
+
+	if guest.ID != 0 {
+		if guest.Name != name {
+			guest.Name = name
+			return s.repository.Update(guest)
+		}
+
+		return guest, nil
+	}
+
+	newGuest, err := s.repository.Save(Guest{Name: name, Email: email})
+	if err != nil {
+		return newGuest, err
+	}
+
+	return newGuest, nil
+}
+
+func (s *service) GetGuestByID(ID int) (Guest, error) {
+	guest, err := s.repository.FindByID(ID)
+	if err != nil {
+		return guest, err
+	}
+
+	return guest, nil
+}
+
+func (s *service) GetGuestByEmail(email string) (Guest, error) {
+	guest, err := s.repository.FindByEmail(normalizeEmail(email))
+	if err != nil {
+		return guest, err
+	}
+
+	return guest, nil
+}
+
+func (s *service) MarkAsMerged(guest Guest, userID int) (Guest, error) {
+	now := time.Now()
+	guest.UserID = userID
+	guest.MergedAt = &now
+
+	updatedGuest, err := s.repository.Update(guest)
+	if err != nil {
+		return updatedGuest, err
+	}
+
+	return updatedGuest, nil
+}
+
+// GenerateReceiptToken signs a token granting access to the receipt of the
+// transaction with the given code until ReceiptLinkTTL has passed.
+func (s *service) GenerateReceiptToken(code string) (string, error) {
+	claim := jwt.MapClaims{}
+	claim["code"] = code
+	claim["exp"] = time.Now().Add(ReceiptLinkTTL).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	signedToken, err := token.SignedString(s.secret)
+
+	if err != nil {
+		return signedToken, err
+	}
+
+	return signedToken, nil
+}
+
+func (s *service) ValidateReceiptToken(code string, encodedToken string) error {
+	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		if !ok {
+			return nil, ErrInvalidReceiptToken
+		}
+
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return ErrInvalidReceiptToken
+	}
+
+	claim, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claim["code"] != code {
+		return ErrInvalidReceiptToken
+	}
+
+	return nil
+}
+
+// SendReceiptLink emails the guest the payment page of their donation and a
+// magic link to view its receipt later.
+func (s *service) SendReceiptLink(guest Guest, code string, paymentURL string) error {
+	token, err := s.GenerateReceiptToken(code)
+	if err != nil {
+		return err
+	}
+
+	receiptURL := fmt.Sprintf("%s/api/v1/guest/transactions/%s?token=%s", s.appURL, url.PathEscape(code), url.QueryEscape(token))
+	pdfURL := fmt.Sprintf("%s/api/v1/guest/transactions/%s/receipt?token=%s", s.appURL, url.PathEscape(code), url.QueryEscape(token))
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Thank you for your donation %s.\n\n"+
+		"Complete your payment here:\n%s\n\n"+
+		"You can view your receipt at any time with this link:\n%s\n\n"+
+		"Once paid, you can download it as a PDF here:\n%s\n\n"+
+		"Register an account with this email to keep all your donations in one place.\n",
+		guest.Name, code, paymentURL, receiptURL, pdfURL)
+
+	return s.mailer.Send(guest.Email, "Your donation "+code, body)
+}
+
+// SendMergeLink emails a newly registered user a link to confirm that the
+// email is theirs, so that the donations made as a guest with it can be moved
+// to their account. Nobody gets another person's donations by registering
+// with their email.
+func (s *service) SendMergeLink(guest Guest, newUser user.User) error {
+	claim := jwt.MapClaims{}
+	claim["guest_id"] = guest.ID
+	claim["user_id"] = newUser.ID
+	claim["email"] = guest.Email
+	claim["exp"] = time.Now().Add(MergeLinkTTL).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	signedToken, err := token.SignedString(s.secret)
+	if err != nil {
+		return err
+	}
+
+	mergeURL := fmt.Sprintf("%s/api/v1/guest/merge?token=%s", s.appURL, url.QueryEscape(signedToken))
+
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"You donated as a guest with this email before. Confirm that this email is yours to move those donations into your account:\n%s\n\n"+
+		"If you did not register an account, you can ignore this email.\n",
+		newUser.Name, mergeURL)
+
+	return s.mailer.Send(guest.Email, "Confirm your email", body)
+}
+
+// VerifyMergeToken checks a link sent by SendMergeLink and returns the guest
+// and the user it was sent for, recording that the user's email is verified.
+// The link is refused when either of them no longer has the email it was
+// sent to.
+func (s *service) VerifyMergeToken(encodedToken string) (Guest, user.User, error) {
+	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		if !ok {
+			return nil, ErrInvalidMergeToken
+		}
+
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return Guest{}, user.User{}, ErrInvalidMergeToken
+	}
+
+	claim, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return Guest{}, user.User{}, ErrInvalidMergeToken
+	}
+
+	email, _ := claim["email"].(string)
+	guestID, _ := claim["guest_id"].(float64)
+	userID, _ := claim["user_id"].(float64)
+
+	guest, err := s.repository.FindByEmail(email)
+	if err != nil {
+		return guest, user.User{}, err
+	}
+
+	if guest.ID == 0 || guest.ID != int(guestID) {
+		return Guest{}, user.User{}, ErrInvalidMergeToken
+	}
+
+	verifiedUser, err := s.userRepository.FindByID(int(userID))
+	if err != nil {
+		return guest, verifiedUser, err
+	}
+
+	if verifiedUser.ID == 0 || normalizeEmail(verifiedUser.Email) != email {
+		return Guest{}, user.User{}, ErrInvalidMergeToken
+	}
+
+	err = s.userRepository.MarkEmailVerified(verifiedUser.ID, time.Now())
+	if err != nil {
+		return guest, verifiedUser, err
+	}
+
+	return guest, verifiedUser, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
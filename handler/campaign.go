@@ -6,7 +6,6 @@ import (
 	"cfa-backend/user"
 	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,20 +20,89 @@ func NewCampaignHandler(campaignService campaign.Service) *campaignHandler {
 
 // GetCampaigns godoc
 // @Summary      Get list of campaign
-// @Description  Get list of campaign by user id
+// @Description  Get a page of campaigns, filtered and sorted. Pages are requested by number or by the next_cursor of the previous page; ending_soon only lists live campaigns, those without an end date last
 // @Tags         Campaigns
 // @Accept       json
 // @Produce      json
-// @Param        user_id query int false "User ID"
+// @Param        user_id     query int      false "Creator user ID"
+// @Param        category    query string   false "Category slug, subcategories included"
+// @Param        tags        query []string false "Tags the campaigns must all carry"
+// @Param        status      query string   false "live, successful, failed or closed"
+// @Param        min_goal    query int      false "Minimum goal amount"
+// @Param        max_goal    query int      false "Maximum goal amount"
+// @Param        min_funded  query int      false "Minimum funded percentage"
+// @Param        max_funded  query int      false "Maximum funded percentage"
+// @Param        sort        query string   false "newest, most_funded, closest_to_goal, ending_soon or most_backers"
+// @Param        page        query int      false "Page number"
+// @Param        cursor      query string   false "Cursor from the previous page"
+// @Param        limit       query int      false "Page size, 1 to 100"
 // @Success      200   {object}  helper.Response
 // @Failure      400   {object}  helper.Response
 // @Failure      422   {object}  helper.Response
 // @Router       /campaigns [get]
 func (h *campaignHandler) GetCampaigns(c *gin.Context) {
-	userID, _ := strconv.Atoi(c.Query("user_id"))
+	var input campaign.GetCampaignsInput
 
-	campaigns, err := h.campaignService.GetCampaigns(userID)
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Error to get campaigns!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	campaigns, page, err := h.campaignService.GetCampaigns(input)
+
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Error to get campaigns!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	campaignsFormatter := campaign.FormatCampaigns(campaigns)
+	response := helper.PaginatedAPIResponse("List of campaigns!", http.StatusOK, "success", campaignsFormatter, campaignPagination(page))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserCampaigns godoc
+// @Summary      Get list of campaign of the current user
+// @Description  Get a page of the campaigns of the current user, drafts and campaigns under review included, filtered and sorted like the public list
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        category    query string   false "Category slug, subcategories included"
+// @Param        tags        query []string false "Tags the campaigns must all carry"
+// @Param        status      query string   false "draft, pending_review, live, successful, failed or closed"
+// @Param        min_goal    query int      false "Minimum goal amount"
+// @Param        max_goal    query int      false "Maximum goal amount"
+// @Param        min_funded  query int      false "Minimum funded percentage"
+// @Param        max_funded  query int      false "Maximum funded percentage"
+// @Param        sort        query string   false "newest, most_funded, closest_to_goal, ending_soon or most_backers"
+// @Param        page        query int      false "Page number"
+// @Param        cursor      query string   false "Cursor from the previous page"
+// @Param        limit       query int      false "Page size, 1 to 100"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /me/campaigns [get]
+func (h *campaignHandler) GetUserCampaigns(c *gin.Context) {
+	var input campaign.GetCampaignsInput
+
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Error to get campaigns!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
 
+	campaigns, page, err := h.campaignService.GetUserCampaigns(input, currentUser)
 	if err != nil {
 		errorMessage := gin.H{"errors": err.Error()}
 
@@ -44,13 +112,55 @@ func (h *campaignHandler) GetCampaigns(c *gin.Context) {
 	}
 
 	campaignsFormatter := campaign.FormatCampaigns(campaigns)
-	response := helper.APIResponse("List of campaigns!", http.StatusOK, "success", campaignsFormatter)
+	response := helper.PaginatedAPIResponse("List of campaigns!", http.StatusOK, "success", campaignsFormatter, campaignPagination(page))
+	c.JSON(http.StatusOK, response)
+}
+
+func campaignPagination(page campaign.Page) helper.Pagination {
+	return helper.Pagination{Limit: page.Limit, Page: page.Number, NextCursor: page.NextCursor, HasMore: page.HasMore, Total: &page.Total}
+}
+
+// SearchCampaigns godoc
+// @Summary      Search campaigns
+// @Description  Search public campaigns by name, description and tags, most relevant first
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        q      query string true  "Search query"
+// @Param        limit  query int    false "Maximum number of results, 1 to 50"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaigns/search [get]
+func (h *campaignHandler) SearchCampaigns(c *gin.Context) {
+	var input campaign.SearchCampaignsInput
+
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to search campaigns!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	results, err := h.campaignService.SearchCampaigns(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to search campaigns!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of campaigns found!", http.StatusOK, "success", campaign.FormatSearchResults(results))
 	c.JSON(http.StatusOK, response)
 }
 
 // GetCampaign godoc
 // @Summary      Get detail of campaign
-// @Description  Get detail of campaign by campaign id
+// @Description  Get detail of campaign by campaign id. Campaigns that are not public yet are only shown to their owner and admins
 // @Tags         Campaigns
 // @Accept       json
 // @Produce      json
@@ -72,7 +182,7 @@ func (h *campaignHandler) GetCampaign(c *gin.Context) {
 		return
 	}
 
-	campaignDetail, err := h.campaignService.GetCampaignByID(input)
+	campaignDetail, err := h.campaignService.GetCampaignByID(input, optionalCurrentUser(c))
 
 	if err != nil {
 		errorMessage := gin.H{"errors": err.Error()}
@@ -87,6 +197,54 @@ func (h *campaignHandler) GetCampaign(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetCampaignBySlug godoc
+// @Summary      Get detail of campaign by slug
+// @Description  Get detail of campaign by its slug. An old slug of a renamed campaign answers with a redirect to the current one. Campaigns that are not public yet are only shown to their owner and admins
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        slug path string true "Campaign slug"
+// @Success      200   {object}  helper.Response
+// @Success      301   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaigns/by-slug/{slug} [get]
+func (h *campaignHandler) GetCampaignBySlug(c *gin.Context) {
+	var input campaign.GetCampaignBySlugInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get detail campaign!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	campaignDetail, err := h.campaignService.GetCampaignBySlug(input, optionalCurrentUser(c))
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get detail campaign!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// The campaign was renamed since the link was shared
+	if campaignDetail.Slug != input.Slug {
+		location := "/api/v1/campaigns/by-slug/" + campaignDetail.Slug
+		data := gin.H{"slug": campaignDetail.Slug, "redirect_to": location}
+
+		c.Header("Location", location)
+		response := helper.APIResponse("Campaign has moved to a new slug!", http.StatusMovedPermanently, "success", data)
+		c.JSON(http.StatusMovedPermanently, response)
+		return
+	}
+
+	response := helper.APIResponse("Detail of campaign!", http.StatusOK, "success", campaign.FormatCampaignDetail(campaignDetail))
+	c.JSON(http.StatusOK, response)
+}
+
 // CreateCampaign godoc
 // @Summary      Create campaign
 // @Description  Create new campaign
@@ -240,3 +398,766 @@ func (h *campaignHandler) UploadImage(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetRewardTiers godoc
+// @Summary      Get list of reward tiers
+// @Description  Get the reward tiers backers can select when donating to a campaign
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /campaign/{id}/reward-tiers [get]
+func (h *campaignHandler) GetRewardTiers(c *gin.Context) {
+	var input campaign.GetCampaignDetailInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get reward tiers!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	rewardTiers, err := h.campaignService.GetRewardTiers(input, optionalCurrentUser(c))
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get reward tiers!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of reward tiers!", http.StatusOK, "success", campaign.FormatRewardTiers(rewardTiers))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRewardTier godoc
+// @Summary      Create reward tier
+// @Description  Add a reward tier to the user campaign
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                       true  "Campaign ID"
+// @Param        body  body  campaign.RewardTierInput  true  "Reward tier data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/reward-tiers [post]
+func (h *campaignHandler) CreateRewardTier(c *gin.Context) {
+	var inputURI campaign.GetCampaignDetailInput
+	var input campaign.RewardTierInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create reward tier!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to create reward tier!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newRewardTier, err := h.campaignService.CreateRewardTier(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create reward tier!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Reward tier has been successfuly created!", http.StatusOK, "success", campaign.FormatRewardTier(newRewardTier))
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateRewardTier godoc
+// @Summary      Update reward tier
+// @Description  Update a reward tier of the user campaign
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                       true  "Campaign ID"
+// @Param        tier_id  path  int                       true  "Reward tier ID"
+// @Param        body     body  campaign.RewardTierInput  true  "Reward tier data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/reward-tiers/{tier_id} [put]
+func (h *campaignHandler) UpdateRewardTier(c *gin.Context) {
+	var inputURI campaign.GetRewardTierInput
+	var input campaign.RewardTierInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to update reward tier!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to update reward tier!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	updatedRewardTier, err := h.campaignService.UpdateRewardTier(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to update reward tier!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Reward tier has been successfuly updated!", http.StatusOK, "success", campaign.FormatRewardTier(updatedRewardTier))
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteRewardTier godoc
+// @Summary      Delete reward tier
+// @Description  Delete a reward tier no backer has selected yet
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int  true  "Campaign ID"
+// @Param        tier_id  path  int  true  "Reward tier ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/reward-tiers/{tier_id} [delete]
+func (h *campaignHandler) DeleteRewardTier(c *gin.Context) {
+	var inputURI campaign.GetRewardTierInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to delete reward tier!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	err = h.campaignService.DeleteRewardTier(inputURI, currentUser)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to delete reward tier!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	data := gin.H{"is_deleted": true}
+	response := helper.APIResponse("Reward tier has been successfuly deleted!", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+// SubmitCampaign godoc
+// @Summary      Submit campaign for review
+// @Description  Submit a draft campaign to the admins for review
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/submit [post]
+func (h *campaignHandler) SubmitCampaign(c *gin.Context) {
+	h.changeCampaignStatus(c, h.campaignService.SubmitCampaign, "Campaign has been successfuly submitted for review!", "Failed to submit campaign!")
+}
+
+// CloseCampaign godoc
+// @Summary      Close campaign
+// @Description  Close a successful or failed campaign
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/close [post]
+func (h *campaignHandler) CloseCampaign(c *gin.Context) {
+	h.changeCampaignStatus(c, h.campaignService.CloseCampaign, "Campaign has been successfuly closed!", "Failed to close campaign!")
+}
+
+// GetCampaignsByStatus godoc
+// @Summary      Get list of campaigns by status
+// @Description  Get list of campaigns by status, for admins
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        status query string false "Campaign status, defaults to pending_review"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/campaigns [get]
+func (h *campaignHandler) GetCampaignsByStatus(c *gin.Context) {
+	campaigns, err := h.campaignService.GetCampaignsByStatus(c.Query("status"))
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaigns!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of campaigns!", http.StatusOK, "success", campaign.FormatCampaigns(campaigns))
+	c.JSON(http.StatusOK, response)
+}
+
+// ApproveCampaign godoc
+// @Summary      Approve campaign
+// @Description  Put a campaign under review live, for admins
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /admin/campaigns/{id}/approve [post]
+func (h *campaignHandler) ApproveCampaign(c *gin.Context) {
+	h.changeCampaignStatus(c, h.campaignService.ApproveCampaign, "Campaign has been successfuly approved!", "Failed to approve campaign!")
+}
+
+// RejectCampaign godoc
+// @Summary      Reject campaign
+// @Description  Send a campaign under review back to its owner as a draft, for admins
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /admin/campaigns/{id}/reject [post]
+func (h *campaignHandler) RejectCampaign(c *gin.Context) {
+	h.changeCampaignStatus(c, h.campaignService.RejectCampaign, "Campaign has been successfuly rejected!", "Failed to reject campaign!")
+}
+
+func (h *campaignHandler) changeCampaignStatus(c *gin.Context, change func(campaign.GetCampaignDetailInput, user.User) (campaign.Campaign, error), successMessage string, failureMessage string) {
+	var input campaign.GetCampaignDetailInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse(failureMessage, http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	updatedCampaign, err := change(input, currentUser)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse(failureMessage, http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(successMessage, http.StatusOK, "success", campaign.FormatCampaign(updatedCampaign))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCategories godoc
+// @Summary      Get list of categories
+// @Description  Get the category hierarchy with the number of live campaigns in every category
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /categories [get]
+func (h *campaignHandler) GetCategories(c *gin.Context) {
+	categories, err := h.campaignService.GetCategories()
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get categories!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of categories!", http.StatusOK, "success", campaign.FormatCategoryNodes(categories))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCategory godoc
+// @Summary      Get detail of category
+// @Description  Get a category with its subcategories and live campaign counts
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Category ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /categories/{id} [get]
+func (h *campaignHandler) GetCategory(c *gin.Context) {
+	var input campaign.GetCategoryInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get category!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	category, err := h.campaignService.GetCategory(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get category!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Detail of category!", http.StatusOK, "success", campaign.FormatCategoryNode(category))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCategory godoc
+// @Summary      Create category
+// @Description  Create a category, for admins
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        body  body  campaign.CategoryInput  true  "Category data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /admin/categories [post]
+func (h *campaignHandler) CreateCategory(c *gin.Context) {
+	var input campaign.CategoryInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to create category!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newCategory, err := h.campaignService.CreateCategory(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create category!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Category has been successfuly created!", http.StatusOK, "success", campaign.FormatCategory(newCategory))
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCategory godoc
+// @Summary      Update category
+// @Description  Rename or move a category, for admins
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                     true  "Category ID"
+// @Param        body  body  campaign.CategoryInput  true  "Category data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /admin/categories/{id} [put]
+func (h *campaignHandler) UpdateCategory(c *gin.Context) {
+	var inputURI campaign.GetCategoryInput
+	var input campaign.CategoryInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to update category!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to update category!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	updatedCategory, err := h.campaignService.UpdateCategory(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to update category!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Category has been successfuly updated!", http.StatusOK, "success", campaign.FormatCategory(updatedCategory))
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCategory godoc
+// @Summary      Delete category
+// @Description  Delete a category without subcategories or campaigns, for admins
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Category ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /admin/categories/{id} [delete]
+func (h *campaignHandler) DeleteCategory(c *gin.Context) {
+	var inputURI campaign.GetCategoryInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to delete category!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	err = h.campaignService.DeleteCategory(inputURI, currentUser)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to delete category!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	data := gin.H{"is_deleted": true}
+	response := helper.APIResponse("Category has been successfuly deleted!", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCampaignUpdates godoc
+// @Summary      Get list of campaign updates
+// @Description  Get the updates of a campaign, newest first. Updates for backers only are locked unless the user backed the campaign
+// @Tags         Campaign Updates
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/updates [get]
+func (h *campaignHandler) GetCampaignUpdates(c *gin.Context) {
+	var input campaign.GetCampaignDetailInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign updates!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	updates, canViewBackersOnly, err := h.campaignService.GetCampaignUpdates(input, optionalCurrentUser(c))
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign updates!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of campaign updates!", http.StatusOK, "success", campaign.FormatCampaignUpdates(updates, canViewBackersOnly))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCampaignUpdate godoc
+// @Summary      Get detail of campaign update
+// @Description  Get an update of a campaign. Updates for backers only are locked unless the user backed the campaign
+// @Tags         Campaign Updates
+// @Accept       json
+// @Produce      json
+// @Param        id         path  int  true  "Campaign ID"
+// @Param        update_id  path  int  true  "Campaign update ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/updates/{update_id} [get]
+func (h *campaignHandler) GetCampaignUpdate(c *gin.Context) {
+	var input campaign.GetCampaignUpdateInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign update!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	update, canViewBackersOnly, err := h.campaignService.GetCampaignUpdate(input, optionalCurrentUser(c))
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign update!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Detail of campaign update!", http.StatusOK, "success", campaign.FormatCampaignUpdate(update, canViewBackersOnly))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCampaignUpdate godoc
+// @Summary      Create campaign update
+// @Description  Publish an update on the user campaign and email it to every backer
+// @Tags         Campaign Updates
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                           true  "Campaign ID"
+// @Param        body  body  campaign.CampaignUpdateInput  true  "Campaign update data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/updates [post]
+func (h *campaignHandler) CreateCampaignUpdate(c *gin.Context) {
+	var inputURI campaign.GetCampaignDetailInput
+	var input campaign.CampaignUpdateInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create campaign update!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to create campaign update!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newUpdate, err := h.campaignService.CreateCampaignUpdate(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create campaign update!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Campaign update has been successfuly published!", http.StatusOK, "success", campaign.FormatCampaignUpdate(newUpdate, true))
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCampaignUpdate godoc
+// @Summary      Update campaign update
+// @Description  Edit an update of the user campaign; backers are not notified again
+// @Tags         Campaign Updates
+// @Accept       json
+// @Produce      json
+// @Param        id         path  int                           true  "Campaign ID"
+// @Param        update_id  path  int                           true  "Campaign update ID"
+// @Param        body       body  campaign.CampaignUpdateInput  true  "Campaign update data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/updates/{update_id} [put]
+func (h *campaignHandler) UpdateCampaignUpdate(c *gin.Context) {
+	var inputURI campaign.GetCampaignUpdateInput
+	var input campaign.CampaignUpdateInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to update campaign update!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to update campaign update!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	updatedUpdate, err := h.campaignService.UpdateCampaignUpdate(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to update campaign update!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Campaign update has been successfuly updated!", http.StatusOK, "success", campaign.FormatCampaignUpdate(updatedUpdate, true))
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCampaignUpdate godoc
+// @Summary      Delete campaign update
+// @Description  Delete an update of the user campaign
+// @Tags         Campaign Updates
+// @Accept       json
+// @Produce      json
+// @Param        id         path  int  true  "Campaign ID"
+// @Param        update_id  path  int  true  "Campaign update ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/updates/{update_id} [delete]
+func (h *campaignHandler) DeleteCampaignUpdate(c *gin.Context) {
+	var inputURI campaign.GetCampaignUpdateInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to delete campaign update!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	err = h.campaignService.DeleteCampaignUpdate(inputURI, currentUser)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to delete campaign update!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	data := gin.H{"is_deleted": true}
+	response := helper.APIResponse("Campaign update has been successfuly deleted!", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadCampaignUpdateImage godoc
+// @Summary      Upload campaign update image
+// @Description  Add an image to an update of the user campaign
+// @Tags         Campaign Updates
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id         path      int   true  "Campaign ID"
+// @Param        update_id  path      int   true  "Campaign update ID"
+// @Param        file       formData  file  true  "Image file"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/updates/{update_id}/images [post]
+func (h *campaignHandler) UploadCampaignUpdateImage(c *gin.Context) {
+	var inputURI campaign.GetCampaignUpdateInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to upload campaign update image!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		data := gin.H{"is_uploaded": false}
+		response := helper.APIResponse("Failed to upload campaign update image!", http.StatusBadRequest, "error", data)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	path := fmt.Sprintf("images/%d-%s", currentUser.ID, file.Filename)
+
+	err = c.SaveUploadedFile(file, path)
+	if err != nil {
+		data := gin.H{"is_uploaded": false}
+		response := helper.APIResponse("Failed to upload campaign update image!", http.StatusBadRequest, "error", data)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	_, err = h.campaignService.SaveCampaignUpdateImage(inputURI, currentUser, path)
+	if err != nil {
+		data := gin.H{"is_uploaded": false}
+		response := helper.APIResponse("Failed to upload campaign update image!", http.StatusBadRequest, "error", data)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	data := gin.H{"is_uploaded": true}
+	response := helper.APIResponse("Campaign update image successfuly uploaded!", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+// optionalCurrentUser returns the signed in user on routes where signing in
+// is optional, or the zero user for anonymous requests.
+func optionalCurrentUser(c *gin.Context) user.User {
+	currentUser, _ := c.Get("currentUser")
+	signedIn, _ := currentUser.(user.User)
+
+	return signedIn
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"cfa-backend/helper"
+	"cfa-backend/ledger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ledgerHandler struct {
+	ledgerService ledger.Service
+}
+
+func NewLedgerHandler(ledgerService ledger.Service) *ledgerHandler {
+	return &ledgerHandler{ledgerService: ledgerService}
+}
+
+// GetCampaignLedger godoc
+// @Summary      Get campaign ledger
+// @Description  Get the ledger balance and entries of a campaign, for admins
+// @Tags         Ledger
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/campaigns/{id}/ledger [get]
+func (h *ledgerHandler) GetCampaignLedger(c *gin.Context) {
+	var input ledger.GetCampaignLedgerInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign ledger!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	balance, err := h.ledgerService.GetCampaignBalance(input.ID)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign ledger!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	entries, err := h.ledgerService.GetCampaignEntries(input.ID)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign ledger!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ledgerFormatter := ledger.FormatCampaignLedger(balance, entries)
+	response := helper.APIResponse("Campaign ledger!", http.StatusOK, "success", ledgerFormatter)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"cfa-backend/payment"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var mockCheckoutTemplate = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Mock Checkout - {{.Charge.OrderID}}</title>
+	<style>
+		body { font-family: sans-serif; max-width: 480px; margin: 40px auto; color: #222; }
+		.box { border: 1px solid #ddd; border-radius: 8px; padding: 24px; }
+		.amount { font-size: 28px; font-weight: bold; margin: 8px 0 24px; }
+		button { padding: 10px 18px; margin-right: 8px; border: 0; border-radius: 4px; cursor: pointer; }
+		.pay { background: #2e7d32; color: #fff; }
+		.fail { background: #c62828; color: #fff; }
+	</style>
+</head>
+<body>
+	<div class="box">
+		<p>Mock payment gateway &mdash; no real money is moved.</p>
+		<h2>{{.Charge.ItemName}}</h2>
+		<p>Order {{.Charge.OrderID}} for {{.Charge.CustomerName}}</p>
+		<div class="amount">Rp {{.Charge.Amount}}</div>
+		{{if eq .Status "pending"}}
+		<form method="post">
+			<p>
+				<label>Payment method
+					<select name="payment_method">
+						<option value="bank_transfer">Bank transfer</option>
+						<option value="gopay">GoPay</option>
+						<option value="qris">QRIS</option>
+						<option value="credit_card">Credit card</option>
+					</select>
+				</label>
+			</p>
+			<button class="pay" name="result" value="success">Pay now</button>
+			<button class="fail" name="result" value="failure">Simulate failure</button>
+		</form>
+		{{else}}
+		<p>Payment status: <strong>{{.Status}}</strong></p>
+		{{end}}
+	</div>
+</body>
+</html>`))
+
+type paymentHandler struct {
+	mockGateway *payment.MockGateway
+}
+
+func NewPaymentHandler(mockGateway *payment.MockGateway) *paymentHandler {
+	return &paymentHandler{mockGateway: mockGateway}
+}
+
+// MockCheckout godoc
+// @Summary      Mock checkout page
+// @Description  Render the fake checkout page of the mock payment gateway
+// @Tags         Payments
+// @Produce      html
+// @Param        token path string true "Checkout token"
+// @Success      200
+// @Failure      404
+// @Router       /payment/mock/checkout/{token} [get]
+func (h *paymentHandler) MockCheckout(c *gin.Context) {
+	mockPayment, err := h.mockGateway.FindByToken(c.Param("token"))
+	if err != nil {
+		c.String(http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	mockCheckoutTemplate.Execute(c.Writer, mockPayment)
+}
+
+// CompleteMockCheckout godoc
+// @Summary      Complete mock checkout
+// @Description  Pay or fail a payment on the mock payment gateway
+// @Tags         Payments
+// @Accept       x-www-form-urlencoded
+// @Produce      html
+// @Param        token path string true "Checkout token"
+// @Success      303
+// @Failure      400
+// @Router       /payment/mock/checkout/{token} [post]
+func (h *paymentHandler) CompleteMockCheckout(c *gin.Context) {
+	token := c.Param("token")
+	success := c.PostForm("result") == "success"
+
+	_, err := h.mockGateway.Complete(token, c.DefaultPostForm("payment_method", "bank_transfer"), success)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, c.Request.URL.Path)
+}
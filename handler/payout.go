@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"cfa-backend/helper"
+	"cfa-backend/payout"
+	"cfa-backend/user"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type payoutHandler struct {
+	payoutService payout.Service
+}
+
+func NewPayoutHandler(payoutService payout.Service) *payoutHandler {
+	return &payoutHandler{payoutService: payoutService}
+}
+
+// SaveBankAccount godoc
+// @Summary      Register bank account
+// @Description  Register a bank account that payouts can be sent to
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        body  body  payout.CreateBankAccountInput  true  "Bank account data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /bank-accounts [post]
+func (h *payoutHandler) SaveBankAccount(c *gin.Context) {
+	var input payout.CreateBankAccountInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to register bank account!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	bankAccount, err := h.payoutService.SaveBankAccount(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to register bank account!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Bank account has been successfuly registered!", http.StatusOK, "success", payout.FormatBankAccount(bankAccount))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBankAccounts godoc
+// @Summary      Get list of bank accounts
+// @Description  Get list of bank accounts of the current user
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /bank-accounts [get]
+func (h *payoutHandler) GetBankAccounts(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	bankAccounts, err := h.payoutService.GetBankAccounts(currentUser.ID)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get bank accounts!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of bank accounts!", http.StatusOK, "success", payout.FormatBankAccounts(bankAccounts))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBalance godoc
+// @Summary      Get campaign balance
+// @Description  Get the balance available for withdrawal of a campaign
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /campaign/{id}/balance [get]
+func (h *payoutHandler) GetBalance(c *gin.Context) {
+	var input payout.GetCampaignPayoutInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign balance!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	balance, err := h.payoutService.GetBalance(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign balance!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Campaign balance!", http.StatusOK, "success", payout.FormatBalance(balance))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCampaignPayouts godoc
+// @Summary      Get list of campaign payouts
+// @Description  Get list of payouts of a campaign
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /campaign/{id}/payouts [get]
+func (h *payoutHandler) GetCampaignPayouts(c *gin.Context) {
+	var input payout.GetCampaignPayoutInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign payouts!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	payouts, err := h.payoutService.GetCampaignPayouts(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get campaign payouts!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of campaign payouts!", http.StatusOK, "success", payout.FormatPayouts(payouts))
+	c.JSON(http.StatusOK, response)
+}
+
+// RequestPayout godoc
+// @Summary      Request payout
+// @Description  Request a withdrawal of the available campaign balance
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                        true  "Campaign ID"
+// @Param        body  body  payout.CreatePayoutInput  true  "Payout data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/payouts [post]
+func (h *payoutHandler) RequestPayout(c *gin.Context) {
+	var inputURI payout.GetCampaignPayoutInput
+	var input payout.CreatePayoutInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputURI.User = currentUser
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to request payout!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to request payout!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	newPayout, err := h.payoutService.RequestPayout(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to request payout!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Payout has been successfuly requested!", http.StatusOK, "success", payout.FormatPayout(newPayout))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPayouts godoc
+// @Summary      Get list of payouts
+// @Description  Get list of payouts by status, for admins
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        status query string false "Payout status, defaults to requested"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/payouts [get]
+func (h *payoutHandler) GetPayouts(c *gin.Context) {
+	payouts, err := h.payoutService.GetPayoutsByStatus(c.Query("status"))
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get payouts!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of payouts!", http.StatusOK, "success", payout.FormatPayouts(payouts))
+	c.JSON(http.StatusOK, response)
+}
+
+// ApprovePayout godoc
+// @Summary      Approve payout
+// @Description  Approve a requested payout, for admins
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                         true  "Payout ID"
+// @Param        body  body  payout.ProcessPayoutInput  false "Note"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/payouts/{id}/approve [post]
+func (h *payoutHandler) ApprovePayout(c *gin.Context) {
+	h.processPayout(c, h.payoutService.ApprovePayout, "Payout has been successfuly approved!", "Failed to approve payout!")
+}
+
+// RejectPayout godoc
+// @Summary      Reject payout
+// @Description  Reject a requested or approved payout, for admins
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                         true  "Payout ID"
+// @Param        body  body  payout.ProcessPayoutInput  false "Note"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/payouts/{id}/reject [post]
+func (h *payoutHandler) RejectPayout(c *gin.Context) {
+	h.processPayout(c, h.payoutService.RejectPayout, "Payout has been successfuly rejected!", "Failed to reject payout!")
+}
+
+// MarkPayoutAsPaid godoc
+// @Summary      Mark payout as paid
+// @Description  Mark an approved payout as transferred, for admins
+// @Tags         Payouts
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                         true  "Payout ID"
+// @Param        body  body  payout.ProcessPayoutInput  false "Note"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/payouts/{id}/paid [post]
+func (h *payoutHandler) MarkPayoutAsPaid(c *gin.Context) {
+	h.processPayout(c, h.payoutService.MarkPayoutAsPaid, "Payout has been successfuly marked as paid!", "Failed to mark payout as paid!")
+}
+
+func (h *payoutHandler) processPayout(c *gin.Context, process func(payout.GetPayoutDetailInput, payout.ProcessPayoutInput) (payout.Payout, error), successMessage string, failureMessage string) {
+	var inputURI payout.GetPayoutDetailInput
+	var input payout.ProcessPayoutInput
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse(failureMessage, http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	// The note is optional, so an empty body is fine.
+	c.ShouldBindJSON(&input)
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	processedPayout, err := process(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse(failureMessage, http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(successMessage, http.StatusOK, "success", payout.FormatPayout(processedPayout))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bytes"
+	"cfa-backend/guest"
+	"cfa-backend/helper"
+	"cfa-backend/receipt"
+	"cfa-backend/user"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type receiptHandler struct {
+	receiptService receipt.Service
+}
+
+func NewReceiptHandler(receiptService receipt.Service) *receiptHandler {
+	return &receiptHandler{receiptService: receiptService}
+}
+
+// GetReceipt godoc
+// @Summary      Download donation receipt
+// @Description  Download the PDF receipt of one of the current user's paid donations
+// @Tags         Receipts
+// @Produce      application/pdf
+// @Param        id path int true "Transaction ID"
+// @Success      200
+// @Failure      400   {object}  helper.Response
+// @Failure      404   {object}  helper.Response
+// @Router       /transactions/{id}/receipt [get]
+func (h *receiptHandler) GetReceipt(c *gin.Context) {
+	var input receipt.GetReceiptInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get receipt!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	donationReceipt, err := h.receiptService.GetReceipt(input)
+	if err == nil {
+		var document bytes.Buffer
+		err = h.receiptService.RenderPDF(donationReceipt, &document)
+		if err == nil {
+			c.Header("Content-Disposition", `attachment; filename="receipt-`+donationReceipt.Transaction.Code+`.pdf"`)
+			c.Data(http.StatusOK, "application/pdf", document.Bytes())
+			return
+		}
+	}
+
+	statusCode := http.StatusBadRequest
+	if errors.Is(err, receipt.ErrReceiptNotFound) {
+		statusCode = http.StatusNotFound
+	}
+
+	errorMessage := gin.H{"errors": err.Error()}
+
+	response := helper.APIResponse("Failed to get receipt!", statusCode, "error", errorMessage)
+	c.JSON(statusCode, response)
+}
+
+// GetGuestReceipt godoc
+// @Summary      Download guest donation receipt
+// @Description  Download the PDF receipt of a paid guest donation through the magic link emailed to the guest
+// @Tags         Receipts
+// @Produce      application/pdf
+// @Param        code   path   string  true  "Transaction code"
+// @Param        token  query  string  true  "Receipt link token"
+// @Success      200
+// @Failure      400   {object}  helper.Response
+// @Failure      401   {object}  helper.Response
+// @Failure      404   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /guest/transactions/{code}/receipt [get]
+func (h *receiptHandler) GetGuestReceipt(c *gin.Context) {
+	var input receipt.GetGuestReceiptInput
+
+	err := c.ShouldBindUri(&input)
+	if err == nil {
+		err = c.ShouldBindQuery(&input)
+	}
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get receipt!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	donationReceipt, err := h.receiptService.GetGuestReceipt(input)
+	if err == nil {
+		var document bytes.Buffer
+		err = h.receiptService.RenderPDF(donationReceipt, &document)
+		if err == nil {
+			c.Header("Content-Disposition", `attachment; filename="receipt-`+donationReceipt.Transaction.Code+`.pdf"`)
+			c.Data(http.StatusOK, "application/pdf", document.Bytes())
+			return
+		}
+	}
+
+	statusCode := http.StatusBadRequest
+	if errors.Is(err, guest.ErrInvalidReceiptToken) {
+		statusCode = http.StatusUnauthorized
+	} else if errors.Is(err, receipt.ErrReceiptNotFound) {
+		statusCode = http.StatusNotFound
+	}
+
+	errorMessage := gin.H{"errors": err.Error()}
+
+	response := helper.APIResponse("Failed to get receipt!", statusCode, "error", errorMessage)
+	c.JSON(statusCode, response)
+}
+
+// VerifyReceipt godoc
+// @Summary      Verify donation receipt
+// @Description  Check the verification code printed as a QR code on a donation receipt
+// @Tags         Receipts
+// @Produce      json
+// @Param        code               query  string  true  "Transaction code"
+// @Param        verification_code  query  string  true  "Verification code"
+// @Success      200   {object}  helper.Response
+// @Failure      404   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /receipts/verify [get]
+func (h *receiptHandler) VerifyReceipt(c *gin.Context) {
+	var input receipt.VerifyReceiptInput
+
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to verify receipt!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	verifiedReceipt, err := h.receiptService.VerifyReceipt(input)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, receipt.ErrInvalidReceipt) {
+			statusCode = http.StatusNotFound
+		}
+
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to verify receipt!", statusCode, "error", errorMessage)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := helper.APIResponse("Receipt is valid!", http.StatusOK, "success", receipt.FormatReceiptVerification(verifiedReceipt))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"cfa-backend/helper"
+	"cfa-backend/reconciliation"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reconciliationHandler struct {
+	reconciliationService reconciliation.Service
+}
+
+func NewReconciliationHandler(reconciliationService reconciliation.Service) *reconciliationHandler {
+	return &reconciliationHandler{reconciliationService: reconciliationService}
+}
+
+// Reconcile godoc
+// @Summary      Reconcile campaign totals
+// @Description  Recompute campaign totals from paid transactions and report mismatches, fixing them when fix is true, for admins
+// @Tags         Reconciliation
+// @Accept       json
+// @Produce      json
+// @Param        body  body  reconciliation.ReconcileInput  false  "Set fix to apply the expected totals, otherwise a dry run"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /admin/reconciliation [post]
+func (h *reconciliationHandler) Reconcile(c *gin.Context) {
+	var input reconciliation.ReconcileInput
+
+	// Without a body this is a dry run.
+	err := c.ShouldBindJSON(&input)
+	if err != nil && !errors.Is(err, io.EOF) {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to reconcile campaign totals!", http.StatusUnprocessableEntity, "error", errorMessage)
+
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	report, err := h.reconciliationService.Reconcile(input.Fix)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to reconcile campaign totals!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Campaign totals reconciliation report!", http.StatusOK, "success", reconciliation.FormatReport(report))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"cfa-backend/helper"
+	"cfa-backend/statement"
+	"cfa-backend/user"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type statementHandler struct {
+	statementService statement.Service
+}
+
+func NewStatementHandler(statementService statement.Service) *statementHandler {
+	return &statementHandler{statementService: statementService}
+}
+
+// GetStatement godoc
+// @Summary      Download annual giving statement
+// @Description  Download the current user's paid donations of a year per campaign, as PDF or CSV
+// @Tags         Statements
+// @Produce      application/pdf
+// @Produce      text/csv
+// @Param        year    path   int     true   "Year"
+// @Param        format  query  string  false  "pdf (default) or csv"
+// @Success      200
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /me/statements/{year} [get]
+func (h *statementHandler) GetStatement(c *gin.Context) {
+	var input statement.GetStatementInput
+	var formatInput statement.StatementFormatInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&input)
+	if err == nil {
+		err = c.ShouldBindQuery(&formatInput)
+	}
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get statement!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	givingStatement, err := h.statementService.GetStatement(input)
+
+	var document bytes.Buffer
+	contentType := "application/pdf"
+	if err == nil {
+		if formatInput.Format == "csv" {
+			contentType = "text/csv; charset=utf-8"
+			err = h.statementService.RenderCSV(givingStatement, &document)
+		} else {
+			formatInput.Format = "pdf"
+			err = h.statementService.RenderPDF(givingStatement, &document)
+		}
+	}
+
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get statement!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="giving-statement-%d.%s"`, input.Year, formatInput.Format))
+	c.Data(http.StatusOK, contentType, document.Bytes())
+}
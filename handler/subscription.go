@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"cfa-backend/helper"
+	"cfa-backend/subscription"
+	"cfa-backend/user"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type subscriptionHandler struct {
+	subscriptionService subscription.Service
+}
+
+func NewSubscriptionHandler(subscriptionService subscription.Service) *subscriptionHandler {
+	return &subscriptionHandler{subscriptionService: subscriptionService}
+}
+
+// CreateSubscription godoc
+// @Summary      Create subscription
+// @Description  Pledge a monthly donation to a campaign; the first cycle is billed right away
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        body  body  subscription.CreateSubscriptionInput  true  "Subscription data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /subscriptions [post]
+func (h *subscriptionHandler) CreateSubscription(c *gin.Context) {
+	var input subscription.CreateSubscriptionInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to create subscription!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newSubscription, firstTransaction, err := h.subscriptionService.CreateSubscription(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create subscription!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	subscriptionFormatter := subscription.FormatNewSubscription(newSubscription, firstTransaction)
+	response := helper.APIResponse("Subscription has been successfuly created!", http.StatusOK, "success", subscriptionFormatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSubscriptions godoc
+// @Summary      Get list of subscriptions
+// @Description  Get list of subscriptions of the current user
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /subscriptions [get]
+func (h *subscriptionHandler) GetSubscriptions(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	subscriptions, err := h.subscriptionService.GetUserSubscriptions(currentUser.ID)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get subscriptions!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of subscriptions!", http.StatusOK, "success", subscription.FormatSubscriptions(subscriptions))
+	c.JSON(http.StatusOK, response)
+}
+
+// PauseSubscription godoc
+// @Summary      Pause subscription
+// @Description  Pause an active subscription so no new cycles are billed
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Subscription ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /subscriptions/{id}/pause [post]
+func (h *subscriptionHandler) PauseSubscription(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.PauseSubscription, "Subscription has been successfuly paused!", "Failed to pause subscription!")
+}
+
+// ResumeSubscription godoc
+// @Summary      Resume subscription
+// @Description  Resume a paused subscription
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Subscription ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /subscriptions/{id}/resume [post]
+func (h *subscriptionHandler) ResumeSubscription(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.ResumeSubscription, "Subscription has been successfuly resumed!", "Failed to resume subscription!")
+}
+
+// CancelSubscription godoc
+// @Summary      Cancel subscription
+// @Description  Cancel a subscription for good
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Subscription ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /subscriptions/{id}/cancel [post]
+func (h *subscriptionHandler) CancelSubscription(c *gin.Context) {
+	h.changeSubscription(c, h.subscriptionService.CancelSubscription, "Subscription has been successfuly cancelled!", "Failed to cancel subscription!")
+}
+
+func (h *subscriptionHandler) changeSubscription(c *gin.Context, change func(subscription.GetSubscriptionDetailInput) (subscription.Subscription, error), successMessage string, failureMessage string) {
+	var input subscription.GetSubscriptionDetailInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse(failureMessage, http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	changedSubscription, err := change(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse(failureMessage, http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(successMessage, http.StatusOK, "success", subscription.FormatSubscription(changedSubscription))
+	c.JSON(http.StatusOK, response)
+}
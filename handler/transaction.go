@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"cfa-backend/guest"
 	"cfa-backend/helper"
+	"cfa-backend/payment"
+	"cfa-backend/spreadsheet"
 	"cfa-backend/transaction"
 	"cfa-backend/user"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -19,17 +25,26 @@ func NewTransactionHandler(transactionService transaction.Service) *transactionH
 
 // GetTransaction godoc
 // @Summary      Get list of campaign transactions
-// @Description  Get list of transactions by campaign id
+// @Description  Get a page of transactions by campaign id, with the campaign's gross, refunded, fee and net raised totals in summary
 // @Tags         Transactions
 // @Accept       json
 // @Produce      json
-// @Param        id query int false "Campaign ID"
+// @Param        id          path   int     true   "Campaign ID"
+// @Param        status      query  string  false  "Transaction status"
+// @Param        from        query  string  false  "First day, YYYY-MM-DD"
+// @Param        to          query  string  false  "Last day, YYYY-MM-DD"
+// @Param        min_amount  query  int     false  "Minimum amount"
+// @Param        max_amount  query  int     false  "Maximum amount"
+// @Param        sort        query  string  false  "newest (default), oldest, amount_desc or amount_asc"
+// @Param        cursor      query  string  false  "Cursor of the next page"
+// @Param        limit       query  int     false  "Page size, 20 by default and at most 100"
 // @Success      200   {object}  helper.Response
 // @Failure      400   {object}  helper.Response
 // @Failure      422   {object}  helper.Response
 // @Router       /campaign/:id/transactions [get]
 func (h *transactionHandler) GetCampaignTransactions(c *gin.Context) {
 	var input transaction.GetCampaignIDTransactionInput
+	var listInput transaction.ListTransactionsInput
 
 	currentUser := c.MustGet("currentUser").(user.User)
 	input.User = currentUser
@@ -43,7 +58,25 @@ func (h *transactionHandler) GetCampaignTransactions(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.transactionService.GetTransactionByID(input)
+	err = c.ShouldBindQuery(&listInput)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign transactions!", http.StatusUnprocessableEntity, "error", errorMessage)
+
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	transactions, page, err := h.transactionService.GetTransactionByID(input, listInput)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign transactions!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	totals, err := h.transactionService.GetCampaignTotals(input)
 	if err != nil {
 		errorMessage := gin.H{"errors": err.Error()}
 		response := helper.APIResponse("Failed to get campaign transactions!", http.StatusBadRequest, "error", errorMessage)
@@ -53,25 +86,44 @@ func (h *transactionHandler) GetCampaignTransactions(c *gin.Context) {
 	}
 
 	transactionsFormatter := transaction.FormatCampaignTransactions(transactions)
-	response := helper.APIResponse("List of campaign transactions!", http.StatusOK, "success", transactionsFormatter)
+	response := helper.PaginatedAPIResponse("List of campaign transactions!", http.StatusOK, "success", transactionsFormatter, transactionPagination(page))
+	response.Summary = transaction.FormatCampaignTotals(totals)
 	c.JSON(http.StatusOK, response)
 }
 
 // GetTransaction godoc
 // @Summary      Get list of user transactions
-// @Description  Get list of transactions by user id
+// @Description  Get a page of transactions of the current user
 // @Tags         Transactions
 // @Accept       json
 // @Produce      json
-// @Param        id query int false "User ID"
+// @Param        status      query  string  false  "Transaction status"
+// @Param        from        query  string  false  "First day, YYYY-MM-DD"
+// @Param        to          query  string  false  "Last day, YYYY-MM-DD"
+// @Param        min_amount  query  int     false  "Minimum amount"
+// @Param        max_amount  query  int     false  "Maximum amount"
+// @Param        sort        query  string  false  "newest (default), oldest, amount_desc or amount_asc"
+// @Param        cursor      query  string  false  "Cursor of the next page"
+// @Param        limit       query  int     false  "Page size, 20 by default and at most 100"
 // @Success      200   {object}  helper.Response
 // @Failure      400   {object}  helper.Response
 // @Failure      422   {object}  helper.Response
-// @Router       /user/:id/transactions [get]
+// @Router       /transactions [get]
 func (h *transactionHandler) GetUserTransactions(c *gin.Context) {
+	var listInput transaction.ListTransactionsInput
+
 	currentUser := c.MustGet("currentUser").(user.User)
 
-	transactions, err := h.transactionService.GetTransactionByUserID(currentUser.ID)
+	err := c.ShouldBindQuery(&listInput)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get user transactions!", http.StatusUnprocessableEntity, "error", errorMessage)
+
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	transactions, page, err := h.transactionService.GetTransactionByUserID(currentUser.ID, listInput)
 	if err != nil {
 		errorMessage := gin.H{"errors": err.Error()}
 		response := helper.APIResponse("Failed to get user transactions!", http.StatusBadRequest, "error", errorMessage)
@@ -81,7 +133,498 @@ func (h *transactionHandler) GetUserTransactions(c *gin.Context) {
 	}
 
 	transactionsFormatter := transaction.FormatUserTransactions(transactions)
-	response := helper.APIResponse("List of user transactions!", http.StatusOK, "success", transactionsFormatter)
+	response := helper.PaginatedAPIResponse("List of user transactions!", http.StatusOK, "success", transactionsFormatter, transactionPagination(page))
+	c.JSON(http.StatusOK, response)
+
+}
+
+// CreateTransaction godoc
+// @Summary      Create transaction
+// @Description  Create a new pending donation for a campaign
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        body  body  transaction.CreateTransactionInput  true  "Transaction create data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /transactions [post]
+func (h *transactionHandler) CreateTransaction(c *gin.Context) {
+	var input transaction.CreateTransactionInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to create transaction!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newTransaction, err := h.transactionService.CreateTransaction(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create transaction!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	transactionFormatter := transaction.FormatTransaction(newTransaction)
+	response := helper.APIResponse("Transaction has been successfuly created!", http.StatusOK, "success", transactionFormatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// PaymentNotification godoc
+// @Summary      Payment notification webhook
+// @Description  Receive a signed payment status notification from the payment gateway
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      404   {object}  helper.Response
+// @Router       /transactions/notification [post]
+func (h *transactionHandler) PaymentNotification(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to process payment notification!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	_, err = h.transactionService.ProcessPaymentNotification(payload)
+	if errors.Is(err, transaction.ErrTransactionNotFound) {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to process payment notification!", http.StatusNotFound, "error", errorMessage)
+
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to process payment notification!", http.StatusUnauthorized, "error", errorMessage)
+
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to process payment notification!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Payment notification processed!", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
+}
+
+// GetTransactionHistory godoc
+// @Summary      Get transaction status history
+// @Description  Get the status timeline of a transaction for the campaign owner or an admin
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Transaction ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /transactions/{id}/history [get]
+func (h *transactionHandler) GetTransactionHistory(c *gin.Context) {
+	var input transaction.GetTransactionDetailInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get transaction history!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	histories, err := h.transactionService.GetTransactionHistory(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get transaction history!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	historiesFormatter := transaction.FormatTransactionStatusHistories(histories)
+	response := helper.APIResponse("Transaction status history!", http.StatusOK, "success", historiesFormatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRefund godoc
+// @Summary      Refund transaction
+// @Description  Refund a paid transaction fully (amount 0) or partially, for the campaign owner or an admin. The refund cannot exceed the funds the campaign has left after payouts; a refund the gateway has not confirmed yet is returned as pending and finished in the background
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                             true  "Transaction ID"
+// @Param        body  body  transaction.CreateRefundInput  true  "Refund data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /transactions/{id}/refunds [post]
+func (h *transactionHandler) CreateRefund(c *gin.Context) {
+	var inputURI transaction.GetTransactionDetailInput
+	var input transaction.CreateRefundInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputURI.User = currentUser
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&inputURI)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to refund transaction!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to refund transaction!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	refund, err := h.transactionService.RefundTransaction(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to refund transaction!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	message := "Transaction has been successfuly refunded!"
+	if refund.Status != transaction.RefundStatusSucceeded {
+		message = "Refund has been requested and is still being processed!"
+	}
+
+	refundFormatter := transaction.FormatRefund(refund)
+	response := helper.APIResponse(message, http.StatusOK, "success", refundFormatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRefunds godoc
+// @Summary      Get transaction refunds
+// @Description  Get list of refunds of a transaction, for the campaign owner or an admin
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Transaction ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /transactions/{id}/refunds [get]
+func (h *transactionHandler) GetRefunds(c *gin.Context) {
+	var input transaction.GetTransactionDetailInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get transaction refunds!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	refunds, err := h.transactionService.GetRefunds(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get transaction refunds!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	refundsFormatter := transaction.FormatRefunds(refunds)
+	response := helper.APIResponse("List of transaction refunds!", http.StatusOK, "success", refundsFormatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTransactionsForReview godoc
+// @Summary      Get transactions flagged for review
+// @Description  Get list of transactions that were paid after they expired or failed and have to be refunded, for admins
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /admin/transactions/review [get]
+func (h *transactionHandler) GetTransactionsForReview(c *gin.Context) {
+	transactions, err := h.transactionService.GetTransactionsForReview()
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get transactions for review!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("List of transactions for review!", http.StatusOK, "success", transaction.FormatFlaggedTransactions(transactions))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCampaignSupporters godoc
+// @Summary      Get campaign supporter wall
+// @Description  Get the public list of recent supporters of a campaign, respecting anonymity
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Campaign ID"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Router       /campaign/{id}/supporters [get]
+func (h *transactionHandler) GetCampaignSupporters(c *gin.Context) {
+	var input transaction.GetCampaignSupportersInput
+	input.User = optionalCurrentUser(c)
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign supporters!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	transactions, err := h.transactionService.GetCampaignSupporters(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to get campaign supporters!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	supportersFormatter := transaction.FormatSupporters(transactions)
+	response := helper.APIResponse("List of campaign supporters!", http.StatusOK, "success", supportersFormatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateGuestTransaction godoc
+// @Summary      Create guest transaction
+// @Description  Donate without an account. A magic link to the receipt is emailed to the guest
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        body  body  transaction.CreateGuestTransactionInput  true  "Guest transaction data"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /guest/transactions [post]
+func (h *transactionHandler) CreateGuestTransaction(c *gin.Context) {
+	var input transaction.CreateGuestTransactionInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse("Failed to create transaction!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	newTransaction, err := h.transactionService.CreateGuestTransaction(input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to create transaction!", http.StatusBadRequest, "error", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse("Transaction has been successfuly created!", http.StatusOK, "success", transaction.FormatTransaction(newTransaction))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGuestTransaction godoc
+// @Summary      Get guest transaction receipt
+// @Description  Get the receipt of a guest transaction through the magic link emailed to the guest
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        code   path   string  true  "Transaction code"
+// @Param        token  query  string  true  "Receipt link token"
+// @Success      200   {object}  helper.Response
+// @Failure      401   {object}  helper.Response
+// @Failure      404   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /guest/transactions/{code} [get]
+func (h *transactionHandler) GetGuestTransaction(c *gin.Context) {
+	var input transaction.GetGuestTransactionInput
+
+	err := c.ShouldBindUri(&input)
+	if err == nil {
+		err = c.ShouldBindQuery(&input)
+	}
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get transaction!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	guestTransaction, err := h.transactionService.GetGuestTransaction(input)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, guest.ErrInvalidReceiptToken) {
+			statusCode = http.StatusUnauthorized
+		} else if errors.Is(err, transaction.ErrTransactionNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to get transaction!", statusCode, "error", errorMessage)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := helper.APIResponse("Transaction receipt!", http.StatusOK, "success", transaction.FormatUserTransaction(guestTransaction))
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfirmGuestMerge godoc
+// @Summary      Confirm guest merge
+// @Description  Move the donations made as a guest to the account the confirmation link emailed on registration was sent for
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        token  query  string  true  "Confirmation link token"
+// @Success      200   {object}  helper.Response
+// @Failure      400   {object}  helper.Response
+// @Failure      401   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /guest/merge [get]
+func (h *transactionHandler) ConfirmGuestMerge(c *gin.Context) {
+	var input transaction.ConfirmGuestMergeInput
+
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to confirm guest donations!", http.StatusUnprocessableEntity, "error", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	merged, err := h.transactionService.ConfirmGuestMerge(input)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, guest.ErrInvalidMergeToken) {
+			statusCode = http.StatusUnauthorized
+		}
+
+		errorMessage := gin.H{"errors": err.Error()}
+
+		response := helper.APIResponse("Failed to confirm guest donations!", statusCode, "error", errorMessage)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	data := gin.H{"merged": merged}
+
+	response := helper.APIResponse("Guest donations have been moved to your account!", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportCampaignTransactions godoc
+// @Summary      Export campaign transactions
+// @Description  Download the transactions of a campaign as a CSV or XLSX spreadsheet
+// @Tags         Transactions
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        id      path   int     true   "Campaign ID"
+// @Param        format  query  string  false  "csv (default) or xlsx"
+// @Param        from    query  string  false  "First day, YYYY-MM-DD"
+// @Param        to      query  string  false  "Last day, YYYY-MM-DD"
+// @Param        status  query  string  false  "Transaction status"
+// @Success      200
+// @Failure      400   {object}  helper.Response
+// @Failure      422   {object}  helper.Response
+// @Router       /campaign/{id}/transactions/export [get]
+func (h *transactionHandler) ExportCampaignTransactions(c *gin.Context) {
+	var inputURI transaction.GetCampaignIDTransactionInput
+	var input transaction.ExportCampaignTransactionsInput
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputURI.User = currentUser
+	input.User = currentUser
+
+	err := c.ShouldBindUri(&inputURI)
+	if err == nil {
+		err = c.ShouldBindQuery(&input)
+	}
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to export campaign transactions!", http.StatusUnprocessableEntity, "error", errorMessage)
+
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	filter, err := h.transactionService.GetExportFilter(inputURI, input)
+	if err != nil {
+		errorMessage := gin.H{"errors": err.Error()}
+		response := helper.APIResponse("Failed to export campaign transactions!", http.StatusBadRequest, "error", errorMessage)
+
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	format := input.Format
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+
+	c.Header("Content-Type", spreadsheet.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="campaign-%d-transactions.%s"`, inputURI.ID, format))
+	c.Status(http.StatusOK)
+
+	// From here on the response is streamed, so failures can only be logged.
+	writer, err := spreadsheet.NewWriter(format, c.Writer)
+	if err == nil {
+		err = writer.WriteRow(transaction.ExportHeader)
+	}
+	if err == nil {
+		err = h.transactionService.ExportTransactions(filter, func(exported transaction.Transaction) error {
+			return writer.WriteRow(transaction.FormatExportRow(exported))
+		})
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		log.Printf("failed to export transactions of campaign %d: %v", inputURI.ID, err)
+		c.Abort()
+	}
+}
 
+func transactionPagination(page transaction.Page) helper.Pagination {
+	return helper.Pagination{Limit: page.Limit, NextCursor: page.NextCursor, HasMore: page.HasMore}
 }
@@ -3,20 +3,23 @@ package handler
 import (
 	"cfa-backend/auth"
 	"cfa-backend/helper"
+	"cfa-backend/transaction"
 	"cfa-backend/user"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type userHandler struct {
-	userService user.Service
-	authService auth.Service
+	userService        user.Service
+	authService        auth.Service
+	transactionService transaction.Service
 }
 
-func NewUserHandler(userService user.Service, authService auth.Service) *userHandler {
-	return &userHandler{userService: userService, authService: authService}
+func NewUserHandler(userService user.Service, authService auth.Service, transactionService transaction.Service) *userHandler {
+	return &userHandler{userService: userService, authService: authService, transactionService: transactionService}
 }
 
 // RegisterUser godoc
@@ -52,6 +55,13 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
+	// Donations made as a guest with the same email move to the account once
+	// the user confirms the email is theirs.
+	err = h.transactionService.RequestGuestMerge(newUser)
+	if err != nil {
+		log.Printf("failed to request a guest merge for user %d: %v", newUser.ID, err)
+	}
+
 	token, err := h.authService.GenerateToken(newUser.ID)
 	if err != nil {
 		errorMessage := gin.H{"errors": err.Error()}
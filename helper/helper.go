@@ -1,10 +1,30 @@
 package helper
 
-import "github.com/go-playground/validator/v10"
+import (
+	"os"
+	"strconv"
 
+	"github.com/go-playground/validator/v10"
+)
+
+// Response is the envelope of every response. Summary carries totals over a
+// whole list, next to the page of it in Data.
 type Response struct {
-	Meta Meta        `json:"meta"`
-	Data interface{} `json:"data"`
+	Meta       Meta        `json:"meta"`
+	Data       interface{} `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Summary    interface{} `json:"summary,omitempty"`
+}
+
+// Pagination describes a page of a cursor paginated list. NextCursor is passed
+// as the cursor query parameter to get the next page and is empty on the last
+// one. Page and Total are only set by lists that support them.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int   `json:"total,omitempty"`
 }
 
 type Meta struct {
@@ -28,6 +48,13 @@ func APIResponse(message string, code int, status string, data interface{}) Resp
 	return jsonResponse
 }
 
+func PaginatedAPIResponse(message string, code int, status string, data interface{}, pagination Pagination) Response {
+	jsonResponse := APIResponse(message, code, status, data)
+	jsonResponse.Pagination = &pagination
+
+	return jsonResponse
+}
+
 func FormatValidationError(err error) []string {
 	var errors []string
 
@@ -37,3 +64,28 @@ func FormatValidationError(err error) []string {
 
 	return errors
 }
+
+func GetEnv(key string, fallback string) string {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+// FormatRupiah formats an amount as Indonesian Rupiah, e.g. "Rp 1.250.000".
+func FormatRupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "." + digits[i:]
+	}
+
+	return sign + "Rp " + digits
+}
@@ -0,0 +1,20 @@
+package idempotency
+
+import "time"
+
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+type IdempotencyKey struct {
+	ID           int
+	Key          string
+	Scope        string
+	Fingerprint  string
+	Status       string
+	StatusCode   int
+	ResponseBody string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
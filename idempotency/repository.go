@@ -0,0 +1,53 @@
+package idempotency
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	FindByKey(scope string, key string) (IdempotencyKey, error)
+	Save(idempotencyKey IdempotencyKey) (IdempotencyKey, error)
+	Update(idempotencyKey IdempotencyKey) (IdempotencyKey, error)
+	Delete(idempotencyKey IdempotencyKey) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindByKey(scope string, key string) (IdempotencyKey, error) {
+	var idempotencyKey IdempotencyKey
+	err := r.db.Where("scope = ? AND `key` = ?", scope, key).Find(&idempotencyKey).Error
+
+	if err != nil {
+		return idempotencyKey, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (r *repository) Save(idempotencyKey IdempotencyKey) (IdempotencyKey, error) {
+	err := r.db.Create(&idempotencyKey).Error
+
+	if err != nil {
+		return idempotencyKey, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (r *repository) Update(idempotencyKey IdempotencyKey) (IdempotencyKey, error) {
+	err := r.db.Save(&idempotencyKey).Error
+
+	if err != nil {
+		return idempotencyKey, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (r *repository) Delete(idempotencyKey IdempotencyKey) error {
+	return r.db.Delete(&idempotencyKey).Error
+}
@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrKeyReused         = errors.New("Idempotency key has already been used with a different request!")
+	ErrRequestInProgress = errors.New("A request with this idempotency key is still being processed!")
+	ErrInvalidKey        = errors.New("Idempotency key must be between 1 and 255 characters!")
+)
+
+// Retention is how long a stored response is replayed for. After that the key
+// may be used again.
+const Retention = 24 * time.Hour
+
+type Service interface {
+	Begin(scope string, key string, fingerprint string) (IdempotencyKey, bool, error)
+	Complete(idempotencyKey IdempotencyKey, statusCode int, responseBody []byte) error
+	Abandon(idempotencyKey IdempotencyKey) error
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
+
+// Fingerprint identifies a request by its method, path and body. The path is
+// the requested one, e.g. /api/v1/campaign/2 rather than the route template,
+// so the same body sent to two different resources never matches.
+func Fingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin claims key for a request. It reports true when the key was already
+// completed by an identical request, in which case the stored response should
+// be replayed instead of handling the request again.
+func (s *service) Begin(scope string, key string, fingerprint string) (IdempotencyKey, bool, error) {
+	if key == "" || len(key) > 255 {
+		return IdempotencyKey{}, false, ErrInvalidKey
+	}
+
+	existing, err := s.repository.FindByKey(scope, key)
+	if err != nil {
+		return existing, false, err
+	}
+
+	if existing.ID != 0 && time.Since(existing.CreatedAt) > Retention {
+		err = s.repository.Delete(existing)
+		if err != nil {
+			return existing, false, err
+		}
+		existing = IdempotencyKey{}
+	}
+
+	if existing.ID != 0 {
+		return s.check(existing, fingerprint)
+	}
+
+	idempotencyKey := IdempotencyKey{
+		Key:         key,
+		Scope:       scope,
+		Fingerprint: fingerprint,
+		Status:      StatusProcessing,
+	}
+
+	newIdempotencyKey, err := s.repository.Save(idempotencyKey)
+	if err != nil {
+		// Another request with the same key won the race for the unique index.
+		existing, findErr := s.repository.FindByKey(scope, key)
+		if findErr != nil || existing.ID == 0 {
+			return newIdempotencyKey, false, err
+		}
+
+		return s.check(existing, fingerprint)
+	}
+
+	return newIdempotencyKey, false, nil
+}
+
+// Complete stores the response of the request that claimed the key.
+func (s *service) Complete(idempotencyKey IdempotencyKey, statusCode int, responseBody []byte) error {
+	idempotencyKey.Status = StatusCompleted
+	idempotencyKey.StatusCode = statusCode
+	idempotencyKey.ResponseBody = string(responseBody)
+
+	_, err := s.repository.Update(idempotencyKey)
+	return err
+}
+
+// Abandon releases the key so that the request can be retried, e.g. after a
+// server error.
+func (s *service) Abandon(idempotencyKey IdempotencyKey) error {
+	return s.repository.Delete(idempotencyKey)
+}
+
+func (s *service) check(existing IdempotencyKey, fingerprint string) (IdempotencyKey, bool, error) {
+	if existing.Fingerprint != fingerprint {
+		return existing, false, ErrKeyReused
+	}
+
+	if existing.Status != StatusCompleted {
+		return existing, false, ErrRequestInProgress
+	}
+
+	return existing, true, nil
+}
@@ -0,0 +1,84 @@
+package ledger
+
+import "time"
+
+// Account types. Backer and campaign accounts are per user and per campaign,
+// the platform fee and payout accounts are single platform-wide accounts.
+const (
+	AccountBacker       = "backer"
+	AccountCampaign     = "campaign"
+	AccountPlatformFees = "platform_fees"
+	AccountPayouts      = "payouts"
+)
+
+// Journal kinds, one for every kind of money movement.
+const (
+	KindDonation = "donation"
+	KindFee      = "fee"
+	KindRefund   = "refund"
+	KindPayout   = "payout"
+)
+
+// LedgerJournal groups the entries of one money movement. Its entries always
+// balance: total debits equal total credits. Reference is unique, which makes
+// posting the same movement twice a no-op.
+type LedgerJournal struct {
+	ID          int
+	Reference   string
+	Kind        string
+	Description string
+	CreatedAt   time.Time
+	Entries     []LedgerEntry `gorm:"foreignKey:JournalID"`
+}
+
+// Validate checks that the journal balances: no negative amounts, something
+// moved, and total debits equal to total credits.
+func (journal LedgerJournal) Validate() error {
+	debits, credits := 0, 0
+	for _, entry := range journal.Entries {
+		if entry.Debit < 0 || entry.Credit < 0 {
+			return ErrUnbalancedJournal
+		}
+
+		debits += entry.Debit
+		credits += entry.Credit
+	}
+
+	if debits != credits || debits == 0 {
+		return ErrUnbalancedJournal
+	}
+
+	return nil
+}
+
+type LedgerEntry struct {
+	ID          int
+	JournalID   int
+	AccountType string
+	AccountID   int
+	Debit       int
+	Credit      int
+	CreatedAt   time.Time
+}
+
+// AccountEntry is a ledger entry of one account together with the journal it
+// was posted in.
+type AccountEntry struct {
+	ID          int
+	Reference   string
+	Kind        string
+	Description string
+	Debit       int
+	Credit      int
+	CreatedAt   time.Time
+}
+
+// CampaignBalance is a campaign's position derived from its ledger account.
+type CampaignBalance struct {
+	CampaignID int
+	Donations  int
+	Fees       int
+	Refunds    int
+	Payouts    int
+	Balance    int
+}
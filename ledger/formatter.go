@@ -0,0 +1,52 @@
+package ledger
+
+import "time"
+
+type CampaignLedgerFormatter struct {
+	CampaignID int                    `json:"campaign_id"`
+	Donations  int                    `json:"donations"`
+	Fees       int                    `json:"fees"`
+	Refunds    int                    `json:"refunds"`
+	Payouts    int                    `json:"payouts"`
+	Balance    int                    `json:"balance"`
+	Entries    []LedgerEntryFormatter `json:"entries"`
+}
+
+type LedgerEntryFormatter struct {
+	ID          int       `json:"id"`
+	Reference   string    `json:"reference"`
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Debit       int       `json:"debit"`
+	Credit      int       `json:"credit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func FormatCampaignLedger(balance CampaignBalance, entries []AccountEntry) CampaignLedgerFormatter {
+	formatter := CampaignLedgerFormatter{
+		CampaignID: balance.CampaignID,
+		Donations:  balance.Donations,
+		Fees:       balance.Fees,
+		Refunds:    balance.Refunds,
+		Payouts:    balance.Payouts,
+		Balance:    balance.Balance,
+	}
+
+	entriesFormatter := []LedgerEntryFormatter{}
+	for _, entry := range entries {
+		entryFormatter := LedgerEntryFormatter{
+			ID:          entry.ID,
+			Reference:   entry.Reference,
+			Kind:        entry.Kind,
+			Description: entry.Description,
+			Debit:       entry.Debit,
+			Credit:      entry.Credit,
+			CreatedAt:   entry.CreatedAt,
+		}
+		entriesFormatter = append(entriesFormatter, entryFormatter)
+	}
+
+	formatter.Entries = entriesFormatter
+
+	return formatter
+}
@@ -0,0 +1,5 @@
+package ledger
+
+type GetCampaignLedgerInput struct {
+	ID int `uri:"id" binding:"required"`
+}
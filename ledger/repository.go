@@ -0,0 +1,80 @@
+package ledger
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	GetEntriesByAccount(accountType string, accountID int) ([]AccountEntry, error)
+	SumEntriesByKind(accountType string, accountID int) ([]KindTotal, error)
+}
+
+// KindTotal is the sum of an account's entries for one journal kind.
+type KindTotal struct {
+	Kind   string
+	Debit  int
+	Credit int
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// PostJournal stores a balanced journal together with its entries. Money
+// moves are posted by the repositories that change the state behind them,
+// passing their own database transaction, so the ledger never falls behind
+// a committed change. Posting a journal whose reference already exists does
+// nothing, so callers can safely post again; the unique index on the
+// reference settles concurrent posts.
+func PostJournal(db *gorm.DB, journal LedgerJournal) error {
+	err := journal.Validate()
+	if err != nil {
+		return err
+	}
+
+	var existing LedgerJournal
+	err = db.Select("id").Where("reference = ?", journal.Reference).Find(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != 0 {
+		return nil
+	}
+
+	return db.Create(&journal).Error
+}
+
+func (r *repository) GetEntriesByAccount(accountType string, accountID int) ([]AccountEntry, error) {
+	var entries []AccountEntry
+	err := r.db.Model(&LedgerEntry{}).
+		Select("ledger_entries.id, ledger_journals.reference, ledger_journals.kind, ledger_journals.description, ledger_entries.debit, ledger_entries.credit, ledger_entries.created_at").
+		Joins("JOIN ledger_journals ON ledger_journals.id = ledger_entries.journal_id").
+		Where("ledger_entries.account_type = ? AND ledger_entries.account_id = ?", accountType, accountID).
+		Order("ledger_entries.id DESC").
+		Scan(&entries).Error
+
+	if err != nil {
+		return entries, err
+	}
+
+	return entries, nil
+}
+
+func (r *repository) SumEntriesByKind(accountType string, accountID int) ([]KindTotal, error) {
+	var totals []KindTotal
+	err := r.db.Model(&LedgerEntry{}).
+		Select("ledger_journals.kind AS kind, SUM(ledger_entries.debit) AS debit, SUM(ledger_entries.credit) AS credit").
+		Joins("JOIN ledger_journals ON ledger_journals.id = ledger_entries.journal_id").
+		Where("ledger_entries.account_type = ? AND ledger_entries.account_id = ?", accountType, accountID).
+		Group("ledger_journals.kind").
+		Scan(&totals).Error
+
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,131 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnbalancedJournal = errors.New("Journal entries do not balance!")
+
+type Service interface {
+	GetCampaignBalance(campaignID int) (CampaignBalance, error)
+	GetCampaignEntries(campaignID int) ([]AccountEntry, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
+
+// NewDonationJournal moves a donation from the backer into the campaign
+// account.
+func NewDonationJournal(transactionID int, campaignID int, backerID int, amount int) LedgerJournal {
+	return LedgerJournal{
+		Reference:   fmt.Sprintf("donation:%d", transactionID),
+		Kind:        KindDonation,
+		Description: fmt.Sprintf("Donation of transaction #%d", transactionID),
+		Entries: []LedgerEntry{
+			{AccountType: AccountBacker, AccountID: backerID, Debit: amount},
+			{AccountType: AccountCampaign, AccountID: campaignID, Credit: amount},
+		},
+	}
+}
+
+// NewFeeJournal moves the fees charged on a donation from the campaign
+// account to the platform fee account.
+func NewFeeJournal(transactionID int, campaignID int, amount int) LedgerJournal {
+	return LedgerJournal{
+		Reference:   fmt.Sprintf("fee:%d", transactionID),
+		Kind:        KindFee,
+		Description: fmt.Sprintf("Fees of transaction #%d", transactionID),
+		Entries: []LedgerEntry{
+			{AccountType: AccountCampaign, AccountID: campaignID, Debit: amount},
+			{AccountType: AccountPlatformFees, Credit: amount},
+		},
+	}
+}
+
+// NewFeeRefundJournal gives the fees of a refunded part of a donation back
+// from the platform fee account to the campaign account. The reference
+// identifies the refund, like the one of NewRefundJournal.
+func NewFeeRefundJournal(reference string, campaignID int, amount int) LedgerJournal {
+	return LedgerJournal{
+		Reference:   "fee-refund:" + reference,
+		Kind:        KindFee,
+		Description: "Fees returned by refund " + reference,
+		Entries: []LedgerEntry{
+			{AccountType: AccountPlatformFees, Debit: amount},
+			{AccountType: AccountCampaign, AccountID: campaignID, Credit: amount},
+		},
+	}
+}
+
+// NewRefundJournal moves refunded money from the campaign account back to
+// the backer. The reference identifies the refund, so it is posted only once.
+func NewRefundJournal(reference string, campaignID int, backerID int, amount int) LedgerJournal {
+	return LedgerJournal{
+		Reference:   "refund:" + reference,
+		Kind:        KindRefund,
+		Description: "Refund " + reference,
+		Entries: []LedgerEntry{
+			{AccountType: AccountCampaign, AccountID: campaignID, Debit: amount},
+			{AccountType: AccountBacker, AccountID: backerID, Credit: amount},
+		},
+	}
+}
+
+// NewPayoutJournal moves withdrawn money from the campaign account to the
+// payout account.
+func NewPayoutJournal(payoutID int, campaignID int, amount int) LedgerJournal {
+	return LedgerJournal{
+		Reference:   fmt.Sprintf("payout:%d", payoutID),
+		Kind:        KindPayout,
+		Description: fmt.Sprintf("Payout #%d", payoutID),
+		Entries: []LedgerEntry{
+			{AccountType: AccountCampaign, AccountID: campaignID, Debit: amount},
+			{AccountType: AccountPayouts, Credit: amount},
+		},
+	}
+}
+
+// GetCampaignBalance derives a campaign's balance from its ledger account:
+// donations credited minus fees, refunds and payouts debited.
+func (s *service) GetCampaignBalance(campaignID int) (CampaignBalance, error) {
+	balance := CampaignBalance{CampaignID: campaignID}
+
+	totals, err := s.repository.SumEntriesByKind(AccountCampaign, campaignID)
+	if err != nil {
+		return balance, err
+	}
+
+	for _, total := range totals {
+		net := total.Debit - total.Credit
+
+		switch total.Kind {
+		case KindDonation:
+			balance.Donations = total.Credit - total.Debit
+		case KindFee:
+			balance.Fees = net
+		case KindRefund:
+			balance.Refunds = net
+		case KindPayout:
+			balance.Payouts = net
+		}
+
+		balance.Balance += total.Credit - total.Debit
+	}
+
+	return balance, nil
+}
+
+func (s *service) GetCampaignEntries(campaignID int) ([]AccountEntry, error) {
+	entries, err := s.repository.GetEntriesByAccount(AccountCampaign, campaignID)
+	if err != nil {
+		return entries, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends plain text emails.
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type smtpMailer struct {
+	address string
+	auth    smtp.Auth
+	from    string
+}
+
+// NewSMTPMailer sends emails through an SMTP server. Authentication is skipped
+// when no username is given.
+func NewSMTPMailer(host string, port string, username string, password string, from string) *smtpMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpMailer{address: host + ":" + port, auth: auth, from: from}
+}
+
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	headers := []string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	err := smtp.SendMail(m.address, m.auth, m.from, []string{to}, []byte(message))
+	if err != nil {
+		return fmt.Errorf("sending email to %s: %w", to, err)
+	}
+
+	return nil
+}
+
+type logMailer struct {
+}
+
+// NewLogMailer writes emails to the log instead of sending them, for
+// development setups without an SMTP server.
+func NewLogMailer() *logMailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(to string, subject string, body string) error {
+	log.Printf("email to %s: %s\n%s", to, subject, body)
+	return nil
+}
@@ -1,15 +1,41 @@
 package main
 
 import (
+	"bytes"
 	"cfa-backend/auth"
 	"cfa-backend/campaign"
+	"cfa-backend/fee"
+	"cfa-backend/guest"
 	"cfa-backend/handler"
 	"cfa-backend/helper"
+	"cfa-backend/idempotency"
+	"cfa-backend/ledger"
+	"cfa-backend/mailer"
+	"cfa-backend/migration"
+	"cfa-backend/payment"
+	"cfa-backend/payout"
+	"cfa-backend/receipt"
+	"cfa-backend/reconciliation"
+	"cfa-backend/statement"
+	"cfa-backend/subscription"
 	"cfa-backend/transaction"
 	"cfa-backend/user"
+	"cfa-backend/worker"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	_ "cfa-backend/docs" // Import dokumentasi Swagger yang dihasilkan
 
@@ -38,50 +64,360 @@ import (
 func main() {
 	// refer https://github.com/go-sql-driver/mysql#dsn-data-source-name for details
 	dsn := "root:@tcp(127.0.0.1:3307)/crowdfunding?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
+	err = migration.Run(db)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
 	//Init Repositories
 	userRepository := user.NewRepository(db)
 	campaignRepository := campaign.NewRepository(db)
 	transactionRepository := transaction.NewRepository(db)
+	idempotencyRepository := idempotency.NewRepository(db)
+	ledgerRepository := ledger.NewRepository(db)
+	payoutRepository := payout.NewRepository(db)
+	reconciliationRepository := reconciliation.NewRepository(db)
+	subscriptionRepository := subscription.NewRepository(db)
+	guestRepository := guest.NewRepository(db)
+
+	//Init Link Secrets, emailed links and receipts are signed with them, so there is no default anybody could sign with
+	guestLinkSecret := helper.GetEnv("GUEST_LINK_SECRET", "")
+	if guestLinkSecret == "" {
+		log.Fatal("GUEST_LINK_SECRET is not set")
+	}
+
+	receiptSecret := helper.GetEnv("RECEIPT_SECRET", "")
+	if receiptSecret == "" {
+		log.Fatal("RECEIPT_SECRET is not set")
+	}
+
+	//Init Payment Gateway, midtrans or the in-process mock when asked for explicitly. Notifications are verified with the server key, so there is no default anybody could sign with
+	appURL := helper.GetEnv("APP_URL", "http://localhost:8080")
+	notificationURL := appURL + "/api/v1/transactions/notification"
+
+	var paymentGateway payment.Gateway
+	var mockGateway *payment.MockGateway
+	switch helper.GetEnv("PAYMENT_GATEWAY", "") {
+	case "midtrans":
+		serverKey := helper.GetEnv("MIDTRANS_SERVER_KEY", "")
+		if serverKey == "" {
+			log.Fatal("MIDTRANS_SERVER_KEY is not set")
+		}
+
+		isProduction := helper.GetEnv("MIDTRANS_ENV", "sandbox") == "production"
+		paymentGateway = payment.NewMidtransGateway(serverKey, isProduction)
+	case "mock":
+		serverKey := helper.GetEnv("MOCK_PAYMENT_SERVER_KEY", "")
+		if serverKey == "" {
+			log.Fatal("MOCK_PAYMENT_SERVER_KEY is not set")
+		}
+
+		mockGateway = payment.NewMockGateway(appURL, notificationURL, serverKey)
+		paymentGateway = mockGateway
+	default:
+		log.Fatal("PAYMENT_GATEWAY must be set to midtrans or mock")
+	}
+
+	//Init Fee Schedule
+	feeSchedule := fee.DefaultSchedule()
+	if feeScheduleFile := helper.GetEnv("FEE_SCHEDULE_FILE", ""); feeScheduleFile != "" {
+		feeSchedule, err = fee.LoadSchedule(feeScheduleFile)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	//Init Pending Transaction TTL, unpaid transactions expire after it
+	pendingTTL, err := time.ParseDuration(helper.GetEnv("PENDING_TRANSACTION_TTL", "24h"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	//Init Mailer, emails are only logged when no SMTP server is configured
+	var emailSender mailer.Mailer = mailer.NewLogMailer()
+	if smtpHost := helper.GetEnv("SMTP_HOST", ""); smtpHost != "" {
+		emailSender = mailer.NewSMTPMailer(
+			smtpHost,
+			helper.GetEnv("SMTP_PORT", "587"),
+			helper.GetEnv("SMTP_USERNAME", ""),
+			helper.GetEnv("SMTP_PASSWORD", ""),
+			helper.GetEnv("MAIL_FROM", "no-reply@cfa.local"),
+		)
+	}
+
+	//Init Search Index, the MySQL FULLTEXT index on campaigns unless SEARCH_INDEX=memory for development
+	searchIndexType := helper.GetEnv("SEARCH_INDEX", "mysql")
+	var searchIndex campaign.SearchIndex = campaign.NewMySQLSearchIndex(db)
+	if searchIndexType == "memory" {
+		searchIndex = campaign.NewMemorySearchIndex()
+	}
 
 	//Init Services
 	userService := user.NewService(userRepository)
 	authService := auth.NewService()
-	campaignService := campaign.NewService(campaignRepository)
-	transactionService := transaction.NewService(transactionRepository, campaignRepository)
+	backerDirectory := transaction.NewBackerDirectory(transactionRepository, emailSender, appURL)
+	campaignService := campaign.NewService(campaignRepository, searchIndex, backerDirectory)
+
+	// The in-memory search index starts out empty
+	if searchIndexType == "memory" {
+		indexed, err := campaignService.RebuildSearchIndex()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		log.Printf("indexed %d campaigns for search", indexed)
+	}
+	ledgerService := ledger.NewService(ledgerRepository)
+	guestService := guest.NewService(guestRepository, userRepository, emailSender, appURL, guestLinkSecret)
+	transactionService := transaction.NewService(transactionRepository, campaignRepository, paymentGateway, feeSchedule, guestService, payoutRepository, pendingTTL)
+	idempotencyService := idempotency.NewService(idempotencyRepository)
+	payoutService := payout.NewService(payoutRepository, campaignRepository, transactionRepository)
+	reconciliationService := reconciliation.NewService(reconciliationRepository)
+	subscriptionService := subscription.NewService(subscriptionRepository, campaignRepository, transactionService, emailSender, pendingTTL)
+	organization := receipt.Organization{
+		Name:    helper.GetEnv("ORG_NAME", "CFA Crowdfunding"),
+		Address: helper.GetEnv("ORG_ADDRESS", ""),
+		Email:   helper.GetEnv("ORG_EMAIL", ""),
+		TaxID:   helper.GetEnv("ORG_TAX_ID", ""),
+	}
+	receiptService := receipt.NewService(transactionRepository, campaignRepository, guestService, organization, appURL, receiptSecret)
+	statementService := statement.NewService(transactionRepository, organization)
+
+	// CLI subcommands, e.g. `go run . reconcile -fix`
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcileCommand(reconciliationService, os.Args[2:]))
+	}
 
 	//Init Handlers
-	userHandler := handler.NewUserHandler(userService, authService)
+	userHandler := handler.NewUserHandler(userService, authService, transactionService)
 	campaignHandler := handler.NewCampaignHandler(campaignService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	payoutHandler := handler.NewPayoutHandler(payoutService)
+	reconciliationHandler := handler.NewReconciliationHandler(reconciliationService)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+	statementHandler := handler.NewStatementHandler(statementService)
 
 	router := gin.Default()
 	router.Static("/images", "./images")
+
+	// Fake checkout page of the mock payment gateway, only served when the mock is in use
+	if mockGateway != nil {
+		paymentHandler := handler.NewPaymentHandler(mockGateway)
+		router.GET("/payment/mock/checkout/:token", paymentHandler.MockCheckout)
+		router.POST("/payment/mock/checkout/:token", paymentHandler.CompleteMockCheckout)
+	}
+
 	api := router.Group("/api/v1")
 
 	// Swagger Docs Endpoint
 	api.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	api.POST("/users", userHandler.RegisterUser)
+	api.POST("/users", idempotencyMiddleware(idempotencyService), userHandler.RegisterUser)
 	api.POST("/sessions", userHandler.Login)
 	api.POST("/email_checkers", userHandler.CheckEmailAvailability)
-	api.POST("/avatars", authMiddleware(authService, userService), userHandler.UploadAvatar)
+	api.POST("/avatars", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), userHandler.UploadAvatar)
 
 	api.GET("/campaigns", campaignHandler.GetCampaigns) //u can use query params such as ../../campaigns?user_id=...
-	api.GET("/campaign/:id", campaignHandler.GetCampaign)
-	api.POST("/campaigns", authMiddleware(authService, userService), campaignHandler.CreateCampaign)
-	api.PUT("/campaign/:id", authMiddleware(authService, userService), campaignHandler.UpdateCampaign)
-	api.POST("/campaign-images", authMiddleware(authService, userService), campaignHandler.UploadImage)
+	api.GET("/campaigns/search", campaignHandler.SearchCampaigns)
+	api.GET("/me/campaigns", authMiddleware(authService, userService), campaignHandler.GetUserCampaigns)
+	api.GET("/campaigns/by-slug/:slug", optionalAuthMiddleware(authService, userService), campaignHandler.GetCampaignBySlug)
+	api.GET("/campaign/:id", optionalAuthMiddleware(authService, userService), campaignHandler.GetCampaign)
+	api.POST("/campaigns", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.CreateCampaign)
+	api.PUT("/campaign/:id", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.UpdateCampaign)
+	api.GET("/campaign/:id/reward-tiers", optionalAuthMiddleware(authService, userService), campaignHandler.GetRewardTiers)
+	api.POST("/campaign/:id/reward-tiers", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.CreateRewardTier)
+	api.PUT("/campaign/:id/reward-tiers/:tier_id", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.UpdateRewardTier)
+	api.DELETE("/campaign/:id/reward-tiers/:tier_id", authMiddleware(authService, userService), campaignHandler.DeleteRewardTier)
+	api.POST("/campaign/:id/submit", authMiddleware(authService, userService), campaignHandler.SubmitCampaign)
+	api.POST("/campaign/:id/close", authMiddleware(authService, userService), campaignHandler.CloseCampaign)
+	api.GET("/campaign/:id/updates", optionalAuthMiddleware(authService, userService), campaignHandler.GetCampaignUpdates)
+	api.GET("/campaign/:id/updates/:update_id", optionalAuthMiddleware(authService, userService), campaignHandler.GetCampaignUpdate)
+	api.POST("/campaign/:id/updates", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.CreateCampaignUpdate)
+	api.PUT("/campaign/:id/updates/:update_id", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.UpdateCampaignUpdate)
+	api.DELETE("/campaign/:id/updates/:update_id", authMiddleware(authService, userService), campaignHandler.DeleteCampaignUpdate)
+	api.POST("/campaign/:id/updates/:update_id/images", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.UploadCampaignUpdateImage)
+	api.GET("/categories", campaignHandler.GetCategories)
+	api.GET("/categories/:id", campaignHandler.GetCategory)
+	api.POST("/campaign-images", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), campaignHandler.UploadImage)
 
 	api.GET("/campaign/:id/transactions", authMiddleware(authService, userService), transactionHandler.GetCampaignTransactions)
+	api.GET("/campaign/:id/transactions/export", authMiddleware(authService, userService), transactionHandler.ExportCampaignTransactions)
+	api.GET("/campaign/:id/supporters", optionalAuthMiddleware(authService, userService), transactionHandler.GetCampaignSupporters)
 	api.GET("/transactions", authMiddleware(authService, userService), transactionHandler.GetUserTransactions)
+	api.POST("/transactions", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), transactionHandler.CreateTransaction)
+	api.POST("/transactions/notification", transactionHandler.PaymentNotification)
+	api.POST("/guest/transactions", idempotencyMiddleware(idempotencyService), transactionHandler.CreateGuestTransaction)
+	api.GET("/guest/transactions/:code", transactionHandler.GetGuestTransaction)
+	api.GET("/guest/transactions/:code/receipt", receiptHandler.GetGuestReceipt)
+	api.GET("/guest/merge", transactionHandler.ConfirmGuestMerge)
+	api.GET("/transactions/:id/history", authMiddleware(authService, userService), transactionHandler.GetTransactionHistory)
+	api.GET("/transactions/:id/receipt", authMiddleware(authService, userService), receiptHandler.GetReceipt)
+	api.GET("/receipts/verify", receiptHandler.VerifyReceipt)
+	api.GET("/me/statements/:year", authMiddleware(authService, userService), statementHandler.GetStatement)
+	api.GET("/transactions/:id/refunds", authMiddleware(authService, userService), transactionHandler.GetRefunds)
+	api.POST("/transactions/:id/refunds", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), transactionHandler.CreateRefund)
+
+	api.GET("/bank-accounts", authMiddleware(authService, userService), payoutHandler.GetBankAccounts)
+	api.POST("/bank-accounts", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), payoutHandler.SaveBankAccount)
+	api.GET("/campaign/:id/balance", authMiddleware(authService, userService), payoutHandler.GetBalance)
+	api.GET("/campaign/:id/payouts", authMiddleware(authService, userService), payoutHandler.GetCampaignPayouts)
+	api.POST("/campaign/:id/payouts", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), payoutHandler.RequestPayout)
+
+	api.GET("/subscriptions", authMiddleware(authService, userService), subscriptionHandler.GetSubscriptions)
+	api.POST("/subscriptions", authMiddleware(authService, userService), idempotencyMiddleware(idempotencyService), subscriptionHandler.CreateSubscription)
+	api.POST("/subscriptions/:id/pause", authMiddleware(authService, userService), subscriptionHandler.PauseSubscription)
+	api.POST("/subscriptions/:id/resume", authMiddleware(authService, userService), subscriptionHandler.ResumeSubscription)
+	api.POST("/subscriptions/:id/cancel", authMiddleware(authService, userService), subscriptionHandler.CancelSubscription)
+
+	admin := api.Group("/admin", authMiddleware(authService, userService), adminMiddleware())
+	admin.GET("/campaigns", campaignHandler.GetCampaignsByStatus)
+	admin.POST("/campaigns/:id/approve", campaignHandler.ApproveCampaign)
+	admin.POST("/campaigns/:id/reject", campaignHandler.RejectCampaign)
+	admin.GET("/campaigns/:id/ledger", ledgerHandler.GetCampaignLedger)
+	admin.POST("/categories", campaignHandler.CreateCategory)
+	admin.PUT("/categories/:id", campaignHandler.UpdateCategory)
+	admin.DELETE("/categories/:id", campaignHandler.DeleteCategory)
+	admin.GET("/payouts", payoutHandler.GetPayouts)
+	admin.POST("/payouts/:id/approve", payoutHandler.ApprovePayout)
+	admin.POST("/payouts/:id/reject", payoutHandler.RejectPayout)
+	admin.POST("/payouts/:id/paid", payoutHandler.MarkPayoutAsPaid)
+	admin.POST("/reconciliation", reconciliationHandler.Reconcile)
+	admin.GET("/transactions/review", transactionHandler.GetTransactionsForReview)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	//Init Background Workers
+	locker := worker.NewMySQLLocker(db)
+	var workers sync.WaitGroup
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(ctx, "expire-pending-transactions", 5*time.Minute, locker, func(ctx context.Context) error {
+			expired, err := transactionService.ExpireStaleTransactions(ctx, pendingTTL)
+			if expired > 0 {
+				log.Printf("resolved %d stale pending transactions", expired)
+			}
+			return err
+		})
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(ctx, "retry-refunds", 5*time.Minute, locker, func(ctx context.Context) error {
+			completed, err := transactionService.RetryRefunds(ctx)
+			if completed > 0 {
+				log.Printf("completed %d pending refunds", completed)
+			}
+			return err
+		})
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(ctx, "bill-subscriptions", 10*time.Minute, locker, func(ctx context.Context) error {
+			billed, err := subscriptionService.BillDueSubscriptions(ctx)
+			if billed > 0 {
+				log.Printf("billed %d subscriptions", billed)
+			}
+			return err
+		})
+	}()
 
-	router.Run()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(ctx, "send-update-notifications", time.Minute, locker, func(ctx context.Context) error {
+			sent, err := campaignService.SendUpdateNotifications(ctx)
+			if sent > 0 {
+				log.Printf("sent %d campaign update emails", sent)
+			}
+			return err
+		})
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker.Run(ctx, "close-ended-campaigns", 5*time.Minute, locker, func(ctx context.Context) error {
+			return closeEndedCampaigns(ctx, campaignService, transactionService)
+		})
+	}()
+
+	server := &http.Server{
+		Addr:    ":" + helper.GetEnv("PORT", "8080"),
+		Handler: router,
+	}
+
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Println("server shutdown:", err)
+	}
+
+	workers.Wait()
+}
+
+// runReconcileCommand reconciles campaign totals from the command line. It is a
+// dry run unless -fix is given, and prints a JSON report unless -format text
+// is given. The exit code is 1 when mismatches remain unfixed.
+func runReconcileCommand(reconciliationService reconciliation.Service, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "overwrite mismatched campaign totals instead of only reporting them")
+	format := flags.String("format", "json", "report format: json or text")
+	flags.Parse(args)
+
+	report, err := reconciliationService.Reconcile(*fix)
+	if err != nil {
+		log.Println(err.Error())
+		return 1
+	}
+
+	formatter := reconciliation.FormatReport(report)
+
+	if *format == "text" {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(writer, "checked %d campaigns, %d mismatched (dry run: %t)\n", formatter.CampaignsChecked, formatter.MismatchCount, formatter.DryRun)
+		fmt.Fprintln(writer, "ID\tNAME\tSTORED AMOUNT\tEXPECTED AMOUNT\tSTORED BACKERS\tEXPECTED BACKERS\tFIXED\tERROR")
+		for _, mismatch := range formatter.Mismatches {
+			fmt.Fprintf(writer, "%d\t%s\t%d\t%d\t%d\t%d\t%t\t%s\n", mismatch.CampaignID, mismatch.Name, mismatch.StoredAmount, mismatch.ExpectedAmount, mismatch.StoredBackers, mismatch.ExpectedBackers, mismatch.Fixed, mismatch.Error)
+		}
+		writer.Flush()
+	} else {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(formatter)
+	}
+
+	for _, mismatch := range formatter.Mismatches {
+		if !mismatch.Fixed {
+			return 1
+		}
+	}
+
+	return 0
 }
 
 func authMiddleware(authService auth.Service, userService user.Service) gin.HandlerFunc {
@@ -127,3 +463,159 @@ func authMiddleware(authService auth.Service, userService user.Service) gin.Hand
 		c.Set("currentUser", user)
 	}
 }
+
+// optionalAuthMiddleware authenticates requests that carry a token like
+// authMiddleware and lets anonymous requests through without a currentUser.
+func optionalAuthMiddleware(authService auth.Service, userService user.Service) gin.HandlerFunc {
+	authenticate := authMiddleware(authService, userService)
+
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			return
+		}
+
+		authenticate(c)
+	}
+}
+
+func adminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser := c.MustGet("currentUser").(user.User)
+
+		if currentUser.Role != user.RoleAdmin {
+			response := helper.APIResponse("Forbidden!", http.StatusForbidden, "error", nil)
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+	}
+}
+
+// responseRecorder keeps a copy of everything written to the response so it
+// can be stored for idempotent replays.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// idempotencyMiddleware makes a request safe to retry when it carries an
+// Idempotency-Key header: the first response is stored and replayed for
+// retries, and reusing the key for a different request is rejected. It must
+// run after authMiddleware so keys are scoped per user; requests without a
+// user are scoped per client IP, so guests cannot replay each other's
+// responses.
+func idempotencyMiddleware(idempotencyService idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response := helper.APIResponse("Failed to read request body!", http.StatusBadRequest, "error", nil)
+			c.AbortWithStatusJSON(http.StatusBadRequest, response)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := "anonymous:" + c.ClientIP()
+		if currentUser, ok := c.Get("currentUser"); ok {
+			scope = fmt.Sprintf("user:%d", currentUser.(user.User).ID)
+		}
+
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		record, replay, err := idempotencyService.Begin(scope, key, fingerprint)
+		if errors.Is(err, idempotency.ErrKeyReused) || errors.Is(err, idempotency.ErrInvalidKey) {
+			errorMessage := gin.H{"errors": err.Error()}
+			response := helper.APIResponse("Invalid idempotency key!", http.StatusUnprocessableEntity, "error", errorMessage)
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+
+		if errors.Is(err, idempotency.ErrRequestInProgress) {
+			errorMessage := gin.H{"errors": err.Error()}
+			response := helper.APIResponse("Request is still being processed!", http.StatusConflict, "error", errorMessage)
+			c.AbortWithStatusJSON(http.StatusConflict, response)
+			return
+		}
+
+		if err != nil {
+			errorMessage := gin.H{"errors": err.Error()}
+			response := helper.APIResponse("Failed to check idempotency key!", http.StatusInternalServerError, "error", errorMessage)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+			return
+		}
+
+		if replay {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", []byte(record.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = idempotencyService.Abandon(record)
+		} else {
+			err = idempotencyService.Complete(record, recorder.Status(), recorder.body.Bytes())
+		}
+
+		if err != nil {
+			log.Printf("failed to store idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// closeEndedCampaigns finishes the campaigns that reached their deadline and
+// refunds the donations of failed all-or-nothing campaigns, closing each one
+// once nothing is left to refund. Campaigns with refunds left over, e.g.
+// donations still pending payment, are retried on the next run.
+func closeEndedCampaigns(ctx context.Context, campaignService campaign.Service, transactionService transaction.Service) error {
+	finished, err := campaignService.FinishEndedCampaigns(ctx)
+	if finished > 0 {
+		log.Printf("finished %d ended campaigns", finished)
+	}
+	if err != nil {
+		return err
+	}
+
+	campaigns, err := campaignService.GetCampaignsAwaitingRefund()
+	if err != nil {
+		return err
+	}
+
+	for _, failedCampaign := range campaigns {
+		remaining, err := transactionService.RefundCampaignTransactions(ctx, failedCampaign.ID, "Campaign did not reach its goal")
+		if err != nil {
+			return err
+		}
+
+		if remaining > 0 {
+			log.Printf("campaign %d has %d donations left to refund", failedCampaign.ID, remaining)
+			continue
+		}
+
+		err = campaignService.MarkCampaignRefunded(failedCampaign)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
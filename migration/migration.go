@@ -0,0 +1,142 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// files holds the schema changes, one file per change. Files are applied in
+// the order of their names, so new files must sort after the existing ones.
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockTimeout is how long, in seconds, an instance waits for another one to
+// finish migrating before giving up.
+const lockTimeout = 60
+
+var ErrLockTimeout = errors.New("Timed out waiting for another instance to finish migrating!")
+
+// SchemaMigration records a migration file that has been applied.
+type SchemaMigration struct {
+	Version   string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
+
+// Run applies the migration files that have not been applied yet. Instances
+// starting at the same time take turns through a MySQL named lock, so every
+// file is applied once. MySQL commits DDL statements straight away, which is
+// why a file that fails halfway has to be repaired by hand before Run is
+// tried again.
+func Run(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", "cfa-migrations", lockTimeout).Scan(&acquired)
+	if err != nil {
+		return err
+	}
+
+	if acquired.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	defer conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", "cfa-migrations")
+
+	err = db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(255) NOT NULL PRIMARY KEY, applied_at DATETIME(3) NOT NULL)").Error
+	if err != nil {
+		return err
+	}
+
+	var applied []string
+	err = db.Model(&SchemaMigration{}).Pluck("version", &applied).Error
+	if err != nil {
+		return err
+	}
+
+	done := map[string]bool{}
+	for _, version := range applied {
+		done[version] = true
+	}
+
+	names, err := fs.Glob(files, "sql/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "sql/"), ".sql")
+		if done[version] {
+			continue
+		}
+
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		for _, statement := range splitStatements(string(content)) {
+			err = db.Exec(statement).Error
+			if err != nil {
+				return fmt.Errorf("migration %s: %w", version, err)
+			}
+		}
+
+		err = db.Create(&SchemaMigration{Version: version, AppliedAt: time.Now()}).Error
+		if err != nil {
+			return err
+		}
+
+		log.Printf("applied migration %s", version)
+	}
+
+	return nil
+}
+
+// splitStatements cuts a migration file into its statements, which end with
+// a semicolon at the end of a line. Lines starting with -- are comments.
+func splitStatements(content string) []string {
+	var statements []string
+	var statement strings.Builder
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		statement.WriteString(line)
+		statement.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(statement.String()), ";"))
+			statement.Reset()
+		}
+	}
+
+	if strings.TrimSpace(statement.String()) != "" {
+		statements = append(statements, strings.TrimSpace(statement.String()))
+	}
+
+	return statements
+}
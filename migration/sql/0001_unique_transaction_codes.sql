@@ -0,0 +1,11 @@
+-- Transaction codes are the order IDs sent to the payment gateway and must be
+-- unique. Rows from before codes were generated get one derived from their ID.
+UPDATE transactions SET code = CONCAT('CFA-LEGACY-', id) WHERE code IS NULL OR code = '';
+
+UPDATE transactions t
+JOIN (SELECT code FROM transactions GROUP BY code HAVING COUNT(*) > 1) duplicates ON duplicates.code = t.code
+SET t.code = CONCAT(t.code, '-', t.id);
+
+ALTER TABLE transactions
+  MODIFY code VARCHAR(64) NOT NULL,
+  ADD UNIQUE INDEX idx_transactions_code (code);
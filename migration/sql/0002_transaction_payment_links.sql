@@ -0,0 +1,4 @@
+-- The Snap token and redirect URL the payment gateway returns for a charge.
+ALTER TABLE transactions
+  ADD COLUMN payment_url VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN payment_token VARCHAR(255) NOT NULL DEFAULT '';
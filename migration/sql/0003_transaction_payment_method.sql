@@ -0,0 +1,3 @@
+-- The payment method the gateway reported, e.g. bank_transfer or gopay.
+ALTER TABLE transactions
+  ADD COLUMN payment_method VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- Every status change of a transaction, with who made it and why.
+CREATE TABLE transaction_status_histories (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  transaction_id INT NOT NULL,
+  from_status VARCHAR(32) NOT NULL DEFAULT '',
+  to_status VARCHAR(32) NOT NULL,
+  actor VARCHAR(64) NOT NULL,
+  reason TEXT,
+  payload TEXT,
+  created_at DATETIME(3) NULL,
+  INDEX idx_transaction_status_histories_transaction_id (transaction_id)
+);
@@ -0,0 +1,5 @@
+-- Stale pending transactions that fail to resolve are retried with a delay.
+ALTER TABLE transactions
+  ADD COLUMN expiry_attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN next_expiry_at DATETIME(3) NULL,
+  ADD INDEX idx_transactions_status_created_at (status, created_at);
@@ -0,0 +1,14 @@
+-- Responses stored for the Idempotency-Key header. The unique index decides
+-- which of two concurrent requests with the same key gets to run.
+CREATE TABLE idempotency_keys (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `key` VARCHAR(255) NOT NULL,
+  scope VARCHAR(64) NOT NULL,
+  fingerprint CHAR(64) NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  status_code INT NOT NULL DEFAULT 0,
+  response_body MEDIUMTEXT,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_idempotency_keys_scope_key (scope, `key`)
+);
@@ -0,0 +1,23 @@
+-- Full and partial refunds. A refund is stored as pending with its gateway
+-- key before the gateway is asked for it, and retried under the same key
+-- while its outcome is unknown.
+ALTER TABLE transactions
+  ADD COLUMN refunded_amount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE refunds (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  transaction_id INT NOT NULL,
+  amount INT NOT NULL,
+  reason TEXT,
+  status VARCHAR(32) NOT NULL,
+  refund_key VARCHAR(64) NOT NULL,
+  requested_by INT NOT NULL DEFAULT 0,
+  failure_reason TEXT,
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at DATETIME(3) NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_refunds_refund_key (refund_key),
+  INDEX idx_refunds_transaction_id (transaction_id),
+  INDEX idx_refunds_status_next_attempt_at (status, next_attempt_at)
+);
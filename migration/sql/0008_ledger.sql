@@ -0,0 +1,22 @@
+-- Double-entry ledger. A journal groups the balanced entries of one money
+-- movement; its reference is unique so a movement is posted only once.
+CREATE TABLE ledger_journals (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  reference VARCHAR(100) NOT NULL,
+  kind VARCHAR(32) NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_ledger_journals_reference (reference)
+);
+
+CREATE TABLE ledger_entries (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  journal_id INT NOT NULL,
+  account_type VARCHAR(32) NOT NULL,
+  account_id INT NOT NULL DEFAULT 0,
+  debit INT NOT NULL DEFAULT 0,
+  credit INT NOT NULL DEFAULT 0,
+  created_at DATETIME(3) NULL,
+  INDEX idx_ledger_entries_journal_id (journal_id),
+  INDEX idx_ledger_entries_account (account_type, account_id)
+);
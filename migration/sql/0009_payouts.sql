@@ -0,0 +1,27 @@
+-- Creator bank accounts and the payouts requested to them.
+CREATE TABLE bank_accounts (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  user_id INT NOT NULL,
+  bank_name VARCHAR(255) NOT NULL,
+  account_number VARCHAR(64) NOT NULL,
+  account_name VARCHAR(255) NOT NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  INDEX idx_bank_accounts_user_id (user_id)
+);
+
+CREATE TABLE payouts (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_id INT NOT NULL,
+  user_id INT NOT NULL,
+  bank_account_id INT NOT NULL,
+  amount INT NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  note TEXT,
+  processed_by INT NOT NULL DEFAULT 0,
+  processed_at DATETIME(3) NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  INDEX idx_payouts_campaign_id_status (campaign_id, status),
+  INDEX idx_payouts_status (status)
+);
@@ -0,0 +1,9 @@
+-- Fees charged on a donation and the net amount left for the campaign. Fees
+-- shrink with refunds, and the net amount is what is left after both.
+-- Donations from before the fee schedule carry no fees.
+ALTER TABLE transactions
+  ADD COLUMN platform_fee INT NOT NULL DEFAULT 0,
+  ADD COLUMN processing_fee INT NOT NULL DEFAULT 0,
+  ADD COLUMN net_amount INT NOT NULL DEFAULT 0;
+
+UPDATE transactions SET net_amount = amount - refunded_amount WHERE status IN ('paid', 'settled');
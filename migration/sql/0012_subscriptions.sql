@@ -0,0 +1,20 @@
+-- Monthly pledges and the transactions billed for them.
+CREATE TABLE subscriptions (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  user_id INT NOT NULL,
+  campaign_id INT NOT NULL,
+  amount INT NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  billing_day INT NOT NULL,
+  next_billing_at DATETIME(3) NOT NULL,
+  last_billed_at DATETIME(3) NULL,
+  cancelled_at DATETIME(3) NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  INDEX idx_subscriptions_user_id (user_id),
+  INDEX idx_subscriptions_status_next_billing_at (status, next_billing_at)
+);
+
+ALTER TABLE transactions
+  ADD COLUMN subscription_id INT NOT NULL DEFAULT 0,
+  ADD INDEX idx_transactions_subscription_id (subscription_id);
@@ -0,0 +1,5 @@
+-- Backers choose how they appear on the public supporter wall.
+ALTER TABLE transactions
+  ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN hide_amount BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN message VARCHAR(1024) NOT NULL DEFAULT '';
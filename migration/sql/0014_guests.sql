@@ -0,0 +1,19 @@
+-- Backers who donated without an account, one per email, and when a user
+-- proved they own their email, which moves those donations to the account.
+CREATE TABLE guests (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  user_id INT NOT NULL DEFAULT 0,
+  merged_at DATETIME(3) NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_guests_email (email)
+);
+
+ALTER TABLE transactions
+  ADD COLUMN guest_id INT NOT NULL DEFAULT 0,
+  ADD INDEX idx_transactions_guest_id (guest_id);
+
+ALTER TABLE users
+  ADD COLUMN email_verified_at DATETIME(3) NULL;
@@ -0,0 +1,48 @@
+-- Reward tiers replace the comma-separated perks of a campaign, and a
+-- donation can claim one tier along with where to ship it.
+CREATE TABLE reward_tiers (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_id INT NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  description TEXT NOT NULL,
+  minimum_pledge INT NOT NULL DEFAULT 0,
+  limited_quantity INT NOT NULL DEFAULT 0,
+  reserved INT NOT NULL DEFAULT 0,
+  estimated_delivery DATETIME(3) NULL,
+  shipping_required BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  INDEX idx_reward_tiers_campaign_id (campaign_id)
+);
+
+ALTER TABLE transactions
+  ADD COLUMN reward_tier_id INT NOT NULL DEFAULT 0,
+  ADD COLUMN shipping_address VARCHAR(500) NOT NULL DEFAULT '',
+  ADD INDEX idx_transactions_reward_tier_id (reward_tier_id);
+
+-- Every perk becomes an unlimited tier at the minimum donation, since any
+-- backer used to get all of them. Titles longer than a tier title allows
+-- keep the whole perk in the description. The perks column is left in place
+-- so the move can be checked against it.
+INSERT INTO reward_tiers (campaign_id, title, description, minimum_pledge, created_at, updated_at)
+WITH RECURSIVE split_perks (campaign_id, perk, rest) AS (
+  SELECT id,
+    TRIM(SUBSTRING_INDEX(perks, ',', 1)),
+    IF(LOCATE(',', perks) > 0, SUBSTRING(perks, LOCATE(',', perks) + 1), '')
+  FROM campaigns
+  WHERE perks IS NOT NULL AND TRIM(perks) <> ''
+  UNION ALL
+  SELECT campaign_id,
+    TRIM(SUBSTRING_INDEX(rest, ',', 1)),
+    IF(LOCATE(',', rest) > 0, SUBSTRING(rest, LOCATE(',', rest) + 1), '')
+  FROM split_perks
+  WHERE rest <> ''
+)
+SELECT campaign_id,
+  LEFT(perk, 100),
+  IF(CHAR_LENGTH(perk) > 100, perk, ''),
+  10000,
+  NOW(3),
+  NOW(3)
+FROM split_perks
+WHERE perk <> '';
@@ -0,0 +1,12 @@
+-- Campaigns go through review before they are live and end at a deadline,
+-- keeping their donations or, all-or-nothing, only when the goal is reached.
+ALTER TABLE campaigns
+  ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'draft',
+  ADD COLUMN funding_model VARCHAR(32) NOT NULL DEFAULT 'keep_what_you_raise',
+  ADD COLUMN starts_at DATETIME(3) NULL,
+  ADD COLUMN ends_at DATETIME(3) NULL,
+  ADD INDEX idx_campaigns_status_ends_at (status, ends_at);
+
+-- Campaigns from before the lifecycle were taking donations already, with no
+-- deadline and keeping whatever they raised.
+UPDATE campaigns SET status = 'live', funding_model = 'keep_what_you_raise';
@@ -0,0 +1,25 @@
+-- Campaigns belong to one category of an admin-managed hierarchy and carry
+-- free-form tags.
+CREATE TABLE categories (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  parent_id INT NOT NULL DEFAULT 0,
+  name VARCHAR(50) NOT NULL,
+  slug VARCHAR(255) NOT NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_categories_slug (slug),
+  INDEX idx_categories_parent_id (parent_id)
+);
+
+CREATE TABLE campaign_tags (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_id INT NOT NULL,
+  name VARCHAR(30) NOT NULL,
+  created_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_campaign_tags_campaign_id_name (campaign_id, name),
+  INDEX idx_campaign_tags_name (name)
+);
+
+ALTER TABLE campaigns
+  ADD COLUMN category_id INT NOT NULL DEFAULT 0,
+  ADD INDEX idx_campaigns_category_id (category_id);
@@ -0,0 +1,4 @@
+-- Campaign search matches the name and descriptions through a FULLTEXT
+-- index; tags are matched by prefix on their own index.
+ALTER TABLE campaigns
+  ADD FULLTEXT INDEX idx_campaigns_search (name, short_description, description);
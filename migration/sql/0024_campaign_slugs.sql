@@ -0,0 +1,23 @@
+-- Campaigns are looked up by a unique slug, and the slugs a campaign used
+-- before being renamed keep pointing at it. Campaigns without a slug get one
+-- derived from their ID, and campaigns sharing a slug all but the first get
+-- theirs numbered.
+UPDATE campaigns SET slug = CONCAT('campaign-', id) WHERE slug IS NULL OR slug = '';
+
+UPDATE campaigns c
+JOIN (SELECT slug, MIN(id) AS first_id FROM campaigns GROUP BY slug HAVING COUNT(*) > 1) duplicates ON duplicates.slug = c.slug
+SET c.slug = CONCAT(c.slug, '-', c.id)
+WHERE c.id <> duplicates.first_id;
+
+ALTER TABLE campaigns
+  MODIFY slug VARCHAR(255) NOT NULL,
+  ADD UNIQUE INDEX idx_campaigns_slug (slug);
+
+CREATE TABLE campaign_slug_histories (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_id INT NOT NULL,
+  slug VARCHAR(255) NOT NULL,
+  created_at DATETIME(3) NULL,
+  UNIQUE INDEX idx_campaign_slug_histories_slug (slug),
+  INDEX idx_campaign_slug_histories_campaign_id (campaign_id)
+);
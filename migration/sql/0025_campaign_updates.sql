@@ -0,0 +1,37 @@
+-- Owners post progress updates on their campaigns, some for backers only, and
+-- every backer is emailed about a new update through a queue that a
+-- background worker sends from and retries.
+CREATE TABLE campaign_updates (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_id INT NOT NULL,
+  user_id INT NOT NULL,
+  title VARCHAR(150) NOT NULL,
+  body TEXT NOT NULL,
+  visibility VARCHAR(16) NOT NULL DEFAULT 'public',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  INDEX idx_campaign_updates_campaign_id (campaign_id)
+);
+
+CREATE TABLE campaign_update_images (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_update_id INT NOT NULL,
+  file_name VARCHAR(255) NOT NULL,
+  created_at DATETIME(3) NULL,
+  INDEX idx_campaign_update_images_campaign_update_id (campaign_update_id)
+);
+
+CREATE TABLE campaign_update_notifications (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  campaign_id INT NOT NULL,
+  campaign_update_id INT NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at DATETIME(3) NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  INDEX idx_campaign_update_notifications_status_next_attempt_at (status, next_attempt_at),
+  INDEX idx_campaign_update_notifications_campaign_update_id (campaign_update_id)
+);
@@ -0,0 +1,6 @@
+-- Payments the gateway reports for transactions that were already expired or
+-- failed are flagged for an operator to refund or apply by hand.
+ALTER TABLE transactions
+  ADD COLUMN review_reason VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN flagged_at DATETIME(3) NULL,
+  ADD INDEX idx_transactions_flagged_at (flagged_at);
@@ -0,0 +1,5 @@
+-- Subscriptions whose billing fails for a reason that may pass are retried
+-- with a growing delay instead of on every worker run.
+ALTER TABLE subscriptions
+  ADD COLUMN billing_attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN next_billing_attempt_at DATETIME(3) NULL;
@@ -0,0 +1,53 @@
+package payment
+
+import "time"
+
+// Normalised payment statuses. Every gateway maps its own status names onto
+// these so the rest of the application never deals with provider specifics.
+const (
+	StatusPending  = "pending"
+	StatusPaid     = "paid"
+	StatusSettled  = "settled"
+	StatusFailed   = "failed"
+	StatusExpired  = "expired"
+	StatusRefunded = "refunded"
+)
+
+// Charge is a payment to collect. Expiry is how long the payment page stays
+// payable; zero leaves it to the gateway's default.
+type Charge struct {
+	OrderID       string
+	Amount        int
+	ItemName      string
+	CustomerName  string
+	CustomerEmail string
+	Expiry        time.Duration
+}
+
+type ChargeResult struct {
+	Token       string
+	RedirectURL string
+}
+
+// StatusResult is the state of a payment at the gateway. Refunds lists the
+// refunds the gateway has made for it, if the gateway reports them.
+type StatusResult struct {
+	OrderID       string
+	Status        string
+	GatewayStatus string
+	PaymentMethod string
+	Amount        int
+	Refunds       []RefundResult
+}
+
+type RefundRequest struct {
+	RefundKey string
+	Amount    int
+	Reason    string
+}
+
+type RefundResult struct {
+	RefundKey string
+	Amount    int
+	Status    string
+}
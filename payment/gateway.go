@@ -0,0 +1,18 @@
+package payment
+
+import "errors"
+
+var ErrPaymentNotFound = errors.New("Payment not found on the gateway!")
+
+// ErrRefundRejected is returned when the gateway answered a refund request
+// with a refusal, so no money has been refunded. Any other refund error leaves
+// it unknown whether the refund went through.
+var ErrRefundRejected = errors.New("Payment gateway rejected the refund!")
+
+type Gateway interface {
+	CreateCharge(charge Charge) (ChargeResult, error)
+	GetStatus(orderID string) (StatusResult, error)
+	Cancel(orderID string) (StatusResult, error)
+	Refund(orderID string, request RefundRequest) (RefundResult, error)
+	ParseNotification(payload []byte) (StatusResult, error)
+}
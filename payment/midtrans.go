@@ -0,0 +1,244 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	midtransSandboxSnapURL    = "https://app.sandbox.midtrans.com/snap/v1"
+	midtransSandboxAPIURL     = "https://api.sandbox.midtrans.com/v2"
+	midtransProductionSnapURL = "https://app.midtrans.com/snap/v1"
+	midtransProductionAPIURL  = "https://api.midtrans.com/v2"
+)
+
+type midtransGateway struct {
+	serverKey string
+	snapURL   string
+	apiURL    string
+	client    *http.Client
+}
+
+func NewMidtransGateway(serverKey string, production bool) *midtransGateway {
+	gateway := &midtransGateway{
+		serverKey: serverKey,
+		snapURL:   midtransSandboxSnapURL,
+		apiURL:    midtransSandboxAPIURL,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+
+	if production {
+		gateway.snapURL = midtransProductionSnapURL
+		gateway.apiURL = midtransProductionAPIURL
+	}
+
+	return gateway
+}
+
+type midtransStatusResponse struct {
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	OrderID           string `json:"order_id"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	PaymentType       string `json:"payment_type"`
+	GrossAmount       string `json:"gross_amount"`
+	Refunds           []struct {
+		RefundKey    string `json:"refund_key"`
+		RefundAmount string `json:"refund_amount"`
+	} `json:"refunds"`
+}
+
+func (g *midtransGateway) CreateCharge(charge Charge) (ChargeResult, error) {
+	body := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     charge.OrderID,
+			"gross_amount": charge.Amount,
+		},
+		"customer_details": map[string]interface{}{
+			"first_name": charge.CustomerName,
+			"email":      charge.CustomerEmail,
+		},
+		"item_details": []map[string]interface{}{
+			{
+				"id":       charge.OrderID,
+				"price":    charge.Amount,
+				"quantity": 1,
+				"name":     charge.ItemName,
+			},
+		},
+	}
+
+	// Snap keeps a payment page payable for a day by default, much longer
+	// than a pending transaction is kept around.
+	if charge.Expiry > 0 {
+		body["expiry"] = map[string]interface{}{
+			"unit":     "minute",
+			"duration": int(math.Ceil(charge.Expiry.Minutes())),
+		}
+	}
+
+	var response struct {
+		Token         string   `json:"token"`
+		RedirectURL   string   `json:"redirect_url"`
+		ErrorMessages []string `json:"error_messages"`
+	}
+
+	statusCode, err := g.do(http.MethodPost, g.snapURL+"/transactions", body, &response)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+
+	if statusCode != http.StatusCreated {
+		return ChargeResult{}, fmt.Errorf("Midtrans rejected the charge: %v", response.ErrorMessages)
+	}
+
+	return ChargeResult{Token: response.Token, RedirectURL: response.RedirectURL}, nil
+}
+
+func (g *midtransGateway) GetStatus(orderID string) (StatusResult, error) {
+	var response midtransStatusResponse
+
+	_, err := g.do(http.MethodGet, g.apiURL+"/"+orderID+"/status", nil, &response)
+	if err != nil {
+		return StatusResult{}, err
+	}
+
+	return g.toStatusResult(response)
+}
+
+func (g *midtransGateway) Cancel(orderID string) (StatusResult, error) {
+	var response midtransStatusResponse
+
+	_, err := g.do(http.MethodPost, g.apiURL+"/"+orderID+"/cancel", nil, &response)
+	if err != nil {
+		return StatusResult{}, err
+	}
+
+	return g.toStatusResult(response)
+}
+
+func (g *midtransGateway) Refund(orderID string, request RefundRequest) (RefundResult, error) {
+	body := map[string]interface{}{
+		"refund_key": request.RefundKey,
+		"amount":     request.Amount,
+		"reason":     request.Reason,
+	}
+
+	var response struct {
+		StatusCode    string `json:"status_code"`
+		StatusMessage string `json:"status_message"`
+		RefundKey     string `json:"refund_key"`
+		RefundAmount  string `json:"refund_amount"`
+	}
+
+	_, err := g.do(http.MethodPost, g.apiURL+"/"+orderID+"/refund", body, &response)
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	// A 4xx status is a refusal; a 5xx one may still have refunded.
+	if strings.HasPrefix(response.StatusCode, "4") {
+		return RefundResult{}, fmt.Errorf("%w %s", ErrRefundRejected, response.StatusMessage)
+	}
+
+	if response.StatusCode != "200" {
+		return RefundResult{}, fmt.Errorf("Midtrans failed to refund: %s", response.StatusMessage)
+	}
+
+	amount, _ := strconv.ParseFloat(response.RefundAmount, 64)
+
+	return RefundResult{RefundKey: response.RefundKey, Amount: int(amount), Status: StatusRefunded}, nil
+}
+
+func (g *midtransGateway) ParseNotification(payload []byte) (StatusResult, error) {
+	return parseNotification(payload, g.serverKey)
+}
+
+func (g *midtransGateway) do(method string, url string, body interface{}, out interface{}) (int, error) {
+	var payload bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&payload).Encode(body)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	request, err := http.NewRequest(method, url, &payload)
+	if err != nil {
+		return 0, err
+	}
+
+	request.SetBasicAuth(g.serverKey, "")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	err = json.NewDecoder(response.Body).Decode(out)
+	if err != nil {
+		return response.StatusCode, err
+	}
+
+	return response.StatusCode, nil
+}
+
+func (g *midtransGateway) toStatusResult(response midtransStatusResponse) (StatusResult, error) {
+	if response.StatusCode == "404" {
+		return StatusResult{}, ErrPaymentNotFound
+	}
+
+	if response.TransactionStatus == "" {
+		return StatusResult{}, errors.New("Midtrans error: " + response.StatusMessage)
+	}
+
+	amount, _ := strconv.ParseFloat(response.GrossAmount, 64)
+
+	refunds := []RefundResult{}
+	for _, refund := range response.Refunds {
+		refundAmount, _ := strconv.ParseFloat(refund.RefundAmount, 64)
+		refunds = append(refunds, RefundResult{RefundKey: refund.RefundKey, Amount: int(refundAmount), Status: StatusRefunded})
+	}
+
+	return StatusResult{
+		OrderID:       response.OrderID,
+		Status:        midtransStatus(response.TransactionStatus, response.FraudStatus),
+		GatewayStatus: response.TransactionStatus,
+		PaymentMethod: response.PaymentType,
+		Amount:        int(amount),
+		Refunds:       refunds,
+	}, nil
+}
+
+// midtransStatus maps a Midtrans transaction_status (and fraud_status for card
+// captures) onto one of the normalised payment statuses.
+func midtransStatus(transactionStatus string, fraudStatus string) string {
+	switch transactionStatus {
+	case "capture":
+		if fraudStatus == "accept" || fraudStatus == "" {
+			return StatusPaid
+		}
+		return StatusPending
+	case "settlement", "partial_refund":
+		return StatusSettled
+	case "deny", "cancel", "failure":
+		return StatusFailed
+	case "expire":
+		return StatusExpired
+	case "refund":
+		return StatusRefunded
+	default:
+		return StatusPending
+	}
+}
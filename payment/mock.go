@@ -0,0 +1,250 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MockPayment is the state the mock gateway keeps for every charge.
+type MockPayment struct {
+	Token          string
+	Charge         Charge
+	Status         string
+	PaymentMethod  string
+	RefundedAmount int
+	Refunds        []RefundResult
+	ExpiresAt      time.Time
+}
+
+// MockGateway is an in-process gateway with its own fake checkout page, so the
+// full donation flow can be exercised without any external service.
+type MockGateway struct {
+	baseURL         string
+	notificationURL string
+	serverKey       string
+	client          *http.Client
+	mu              sync.Mutex
+	payments        map[string]*MockPayment
+	tokens          map[string]string
+}
+
+// NewMockGateway creates a mock gateway whose checkout pages live under
+// baseURL and which posts signed notifications to notificationURL, just like
+// a real gateway would.
+func NewMockGateway(baseURL string, notificationURL string, serverKey string) *MockGateway {
+	return &MockGateway{
+		baseURL:         baseURL,
+		notificationURL: notificationURL,
+		serverKey:       serverKey,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		payments:        map[string]*MockPayment{},
+		tokens:          map[string]string{},
+	}
+}
+
+func (g *MockGateway) CreateCharge(charge Charge) (ChargeResult, error) {
+	tokenBytes := make([]byte, 16)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.payments[charge.OrderID]; exists {
+		return ChargeResult{}, errors.New("Order ID has already been charged!")
+	}
+
+	payment := &MockPayment{Token: token, Charge: charge, Status: StatusPending}
+	if charge.Expiry > 0 {
+		payment.ExpiresAt = time.Now().Add(charge.Expiry)
+	}
+
+	g.payments[charge.OrderID] = payment
+	g.tokens[token] = charge.OrderID
+
+	return ChargeResult{Token: token, RedirectURL: g.baseURL + "/payment/mock/checkout/" + token}, nil
+}
+
+func (g *MockGateway) GetStatus(orderID string) (StatusResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[orderID]
+	if !ok {
+		return StatusResult{}, ErrPaymentNotFound
+	}
+	payment.expireIfDue()
+
+	return payment.statusResult(), nil
+}
+
+func (g *MockGateway) Cancel(orderID string) (StatusResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[orderID]
+	if !ok {
+		return StatusResult{}, ErrPaymentNotFound
+	}
+	payment.expireIfDue()
+
+	if payment.Status != StatusPending {
+		return StatusResult{}, errors.New("Only pending payments can be cancelled!")
+	}
+
+	payment.Status = StatusFailed
+
+	return payment.statusResult(), nil
+}
+
+// Refund refunds a paid payment. Like a real gateway, a refund key that has
+// been used already returns the earlier refund instead of refunding again.
+func (g *MockGateway) Refund(orderID string, request RefundRequest) (RefundResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[orderID]
+	if !ok {
+		return RefundResult{}, ErrPaymentNotFound
+	}
+
+	for _, refund := range payment.Refunds {
+		if refund.RefundKey == request.RefundKey {
+			return refund, nil
+		}
+	}
+
+	if payment.Status != StatusPaid && payment.Status != StatusSettled {
+		return RefundResult{}, fmt.Errorf("%w Only paid payments can be refunded!", ErrRefundRejected)
+	}
+
+	if request.Amount <= 0 || payment.RefundedAmount+request.Amount > payment.Charge.Amount {
+		return RefundResult{}, fmt.Errorf("%w Refund amount exceeds the paid amount!", ErrRefundRejected)
+	}
+
+	payment.RefundedAmount += request.Amount
+	if payment.RefundedAmount == payment.Charge.Amount {
+		payment.Status = StatusRefunded
+	}
+
+	refund := RefundResult{RefundKey: request.RefundKey, Amount: request.Amount, Status: StatusRefunded}
+	payment.Refunds = append(payment.Refunds, refund)
+
+	return refund, nil
+}
+
+// FindByToken returns a copy of the payment behind a checkout token.
+func (g *MockGateway) FindByToken(token string) (MockPayment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	orderID, ok := g.tokens[token]
+	if !ok {
+		return MockPayment{}, ErrPaymentNotFound
+	}
+
+	return *g.payments[orderID], nil
+}
+
+// Complete settles (or fails) a pending payment from the fake checkout page.
+func (g *MockGateway) Complete(token string, paymentMethod string, success bool) (MockPayment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	orderID, ok := g.tokens[token]
+	if !ok {
+		return MockPayment{}, ErrPaymentNotFound
+	}
+
+	payment := g.payments[orderID]
+	if payment.expireIfDue() {
+		go g.notify(*payment, "expire")
+	}
+
+	if payment.Status == StatusExpired {
+		return MockPayment{}, errors.New("Payment has expired!")
+	}
+
+	if payment.Status != StatusPending {
+		return MockPayment{}, errors.New("Payment has already been completed!")
+	}
+
+	payment.PaymentMethod = paymentMethod
+	payment.Status = StatusFailed
+	gatewayStatus := "failure"
+	if success {
+		payment.Status = StatusSettled
+		gatewayStatus = "settlement"
+	}
+
+	go g.notify(*payment, gatewayStatus)
+
+	return *payment, nil
+}
+
+func (g *MockGateway) ParseNotification(payload []byte) (StatusResult, error) {
+	return parseNotification(payload, g.serverKey)
+}
+
+// notify delivers a signed notification for the payment to the application.
+func (g *MockGateway) notify(payment MockPayment, gatewayStatus string) {
+	notification := Notification{
+		OrderID:           payment.Charge.OrderID,
+		StatusCode:        "200",
+		GrossAmount:       strconv.Itoa(payment.Charge.Amount) + ".00",
+		TransactionStatus: gatewayStatus,
+		PaymentType:       payment.PaymentMethod,
+	}
+	notification.SignatureKey = signNotification(notification, g.serverKey)
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		log.Println("mock gateway: failed to encode notification:", err)
+		return
+	}
+
+	response, err := g.client.Post(g.notificationURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Println("mock gateway: failed to send notification:", err)
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		log.Printf("mock gateway: notification for %s rejected with status %d", payment.Charge.OrderID, response.StatusCode)
+	}
+}
+
+// expireIfDue expires a pending payment whose expiry has passed, the way a
+// real gateway stops accepting payment for it, and reports whether it did.
+func (p *MockPayment) expireIfDue() bool {
+	if p.Status != StatusPending || p.ExpiresAt.IsZero() || time.Now().Before(p.ExpiresAt) {
+		return false
+	}
+
+	p.Status = StatusExpired
+	return true
+}
+
+func (p *MockPayment) statusResult() StatusResult {
+	return StatusResult{
+		OrderID:       p.Charge.OrderID,
+		Status:        p.Status,
+		GatewayStatus: p.Status,
+		PaymentMethod: p.PaymentMethod,
+		Amount:        p.Charge.Amount,
+		Refunds:       append([]RefundResult{}, p.Refunds...),
+	}
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+var ErrInvalidSignature = errors.New("Invalid notification signature!")
+
+// Notification is the HTTP notification body sent by the gateway whenever a
+// payment changes status. Both gateways use the Midtrans format.
+type Notification struct {
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	PaymentType       string `json:"payment_type"`
+}
+
+// signNotification computes SHA512(order_id + status_code + gross_amount + server_key).
+func signNotification(notification Notification, serverKey string) string {
+	hash := sha512.Sum512([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + serverKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// parseNotification decodes a notification payload and verifies its signature
+// against the server key before trusting any of its content.
+func parseNotification(payload []byte, serverKey string) (StatusResult, error) {
+	var notification Notification
+
+	err := json.Unmarshal(payload, &notification)
+	if err != nil {
+		return StatusResult{}, err
+	}
+
+	expected := signNotification(notification, serverKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(notification.SignatureKey)) != 1 {
+		return StatusResult{}, ErrInvalidSignature
+	}
+
+	amount, err := strconv.ParseFloat(notification.GrossAmount, 64)
+	if err != nil {
+		return StatusResult{}, err
+	}
+
+	return StatusResult{
+		OrderID:       notification.OrderID,
+		Status:        midtransStatus(notification.TransactionStatus, notification.FraudStatus),
+		GatewayStatus: notification.TransactionStatus,
+		PaymentMethod: notification.PaymentType,
+		Amount:        int(amount),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package payout
+
+import (
+	"cfa-backend/campaign"
+	"time"
+)
+
+const (
+	StatusRequested = "requested"
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusPaid      = "paid"
+)
+
+type BankAccount struct {
+	ID            int
+	UserID        int
+	BankName      string
+	AccountNumber string
+	AccountName   string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Payout struct {
+	ID            int
+	CampaignID    int
+	UserID        int
+	BankAccountID int
+	Amount        int
+	Status        string
+	Note          string
+	ProcessedBy   int
+	ProcessedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	BankAccount   BankAccount
+	Campaign      campaign.Campaign
+}
+
+// Balance is what a campaign has available for withdrawal: the settled
+// donations it received minus their fees and what has already been
+// withdrawn or requested.
+type Balance struct {
+	CampaignID int
+	Settled    int
+	Fees       int
+	Withdrawn  int
+	Available  int
+}
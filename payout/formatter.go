@@ -0,0 +1,89 @@
+package payout
+
+import "time"
+
+type BankAccountFormatter struct {
+	ID            int    `json:"id"`
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+func FormatBankAccount(bankAccount BankAccount) BankAccountFormatter {
+	formatter := BankAccountFormatter{
+		ID:            bankAccount.ID,
+		BankName:      bankAccount.BankName,
+		AccountNumber: bankAccount.AccountNumber,
+		AccountName:   bankAccount.AccountName,
+	}
+
+	return formatter
+}
+
+func FormatBankAccounts(bankAccounts []BankAccount) []BankAccountFormatter {
+	bankAccountsFormatter := []BankAccountFormatter{}
+
+	for _, bankAccount := range bankAccounts {
+		formatter := FormatBankAccount(bankAccount)
+		bankAccountsFormatter = append(bankAccountsFormatter, formatter)
+	}
+
+	return bankAccountsFormatter
+}
+
+type BalanceFormatter struct {
+	CampaignID int `json:"campaign_id"`
+	Settled    int `json:"settled"`
+	Fees       int `json:"fees"`
+	Withdrawn  int `json:"withdrawn"`
+	Available  int `json:"available"`
+}
+
+func FormatBalance(balance Balance) BalanceFormatter {
+	formatter := BalanceFormatter{
+		CampaignID: balance.CampaignID,
+		Settled:    balance.Settled,
+		Fees:       balance.Fees,
+		Withdrawn:  balance.Withdrawn,
+		Available:  balance.Available,
+	}
+
+	return formatter
+}
+
+type PayoutFormatter struct {
+	ID          int                  `json:"id"`
+	CampaignID  int                  `json:"campaign_id"`
+	Amount      int                  `json:"amount"`
+	Status      string               `json:"status"`
+	Note        string               `json:"note"`
+	ProcessedAt *time.Time           `json:"processed_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+	BankAccount BankAccountFormatter `json:"bank_account"`
+}
+
+func FormatPayout(payout Payout) PayoutFormatter {
+	formatter := PayoutFormatter{
+		ID:          payout.ID,
+		CampaignID:  payout.CampaignID,
+		Amount:      payout.Amount,
+		Status:      payout.Status,
+		Note:        payout.Note,
+		ProcessedAt: payout.ProcessedAt,
+		CreatedAt:   payout.CreatedAt,
+		BankAccount: FormatBankAccount(payout.BankAccount),
+	}
+
+	return formatter
+}
+
+func FormatPayouts(payouts []Payout) []PayoutFormatter {
+	payoutsFormatter := []PayoutFormatter{}
+
+	for _, payout := range payouts {
+		formatter := FormatPayout(payout)
+		payoutsFormatter = append(payoutsFormatter, formatter)
+	}
+
+	return payoutsFormatter
+}
@@ -0,0 +1,30 @@
+package payout
+
+import "cfa-backend/user"
+
+type CreateBankAccountInput struct {
+	BankName      string `json:"bank_name" binding:"required"`
+	AccountNumber string `json:"account_number" binding:"required,numeric"`
+	AccountName   string `json:"account_name" binding:"required"`
+	User          user.User
+}
+
+type GetCampaignPayoutInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type CreatePayoutInput struct {
+	BankAccountID int `json:"bank_account_id" binding:"required"`
+	Amount        int `json:"amount" binding:"required"`
+	User          user.User
+}
+
+type GetPayoutDetailInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type ProcessPayoutInput struct {
+	Note string `json:"note"`
+	User user.User
+}
@@ -0,0 +1,191 @@
+package payout
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/ledger"
+	"cfa-backend/transaction"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("Payout amount exceeds the available balance!")
+	ErrPayoutProcessed     = errors.New("Payout has already been processed by someone else!")
+)
+
+type Repository interface {
+	SaveBankAccount(bankAccount BankAccount) (BankAccount, error)
+	FindBankAccountsByUserID(userID int) ([]BankAccount, error)
+	FindBankAccountByID(ID int) (BankAccount, error)
+	FindByID(ID int) (Payout, error)
+	FindByCampaignID(campaignID int) ([]Payout, error)
+	FindByStatus(status string) ([]Payout, error)
+	SumWithdrawnByCampaignID(campaignID int) (int, error)
+	SaveWithinBalance(payout Payout, transactionRepository transaction.Repository) (Payout, error)
+	Process(payout Payout, fromStatus string) (Payout, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// withdrawnStatuses are the payout statuses that use up a campaign's balance.
+var withdrawnStatuses = []string{StatusRequested, StatusApproved, StatusPaid}
+
+func (r *repository) SaveBankAccount(bankAccount BankAccount) (BankAccount, error) {
+	err := r.db.Create(&bankAccount).Error
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+func (r *repository) FindBankAccountsByUserID(userID int) ([]BankAccount, error) {
+	var bankAccounts []BankAccount
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&bankAccounts).Error
+
+	if err != nil {
+		return bankAccounts, err
+	}
+
+	return bankAccounts, nil
+}
+
+func (r *repository) FindBankAccountByID(ID int) (BankAccount, error) {
+	var bankAccount BankAccount
+	err := r.db.Where("id = ?", ID).Find(&bankAccount).Error
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+func (r *repository) FindByID(ID int) (Payout, error) {
+	var payout Payout
+	err := r.db.Preload("BankAccount").Preload("Campaign").Where("id = ?", ID).Find(&payout).Error
+
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+func (r *repository) FindByCampaignID(campaignID int) ([]Payout, error) {
+	var payouts []Payout
+	err := r.db.Preload("BankAccount").Where("campaign_id = ?", campaignID).Order("id DESC").Find(&payouts).Error
+
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+func (r *repository) FindByStatus(status string) ([]Payout, error) {
+	var payouts []Payout
+	err := r.db.Preload("BankAccount").Preload("Campaign").Where("status = ?", status).Order("id ASC").Find(&payouts).Error
+
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+func (r *repository) SumWithdrawnByCampaignID(campaignID int) (int, error) {
+	return sumWithdrawn(r.db, campaignID)
+}
+
+// SaveWithinBalance stores a payout request only if the campaign still has
+// enough available balance: its settled donations net of fees and open
+// refunds, minus what has been withdrawn already. The campaign row is locked
+// before the balance is read, the same lock refunds take, so concurrent
+// payouts and refunds cannot hand out the same money twice.
+func (r *repository) SaveWithinBalance(payout Payout, transactionRepository transaction.Repository) (Payout, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var locked campaign.Campaign
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", payout.CampaignID).Find(&locked).Error
+		if err != nil {
+			return err
+		}
+
+		settled, err := transactionRepository.SumSettledByCampaignID(payout.CampaignID)
+		if err != nil {
+			return err
+		}
+		received := settled.Amount - settled.Fees
+
+		withdrawn, err := sumWithdrawn(tx, payout.CampaignID)
+		if err != nil {
+			return err
+		}
+
+		if payout.Amount > received-withdrawn {
+			return ErrInsufficientBalance
+		}
+
+		return tx.Create(&payout).Error
+	})
+
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+// Process stores the new status of a payout, but only if it is still in
+// fromStatus; otherwise another admin got there first and ErrPayoutProcessed
+// is returned. A payout that becomes paid is posted to the ledger in the same
+// database transaction.
+func (r *repository) Process(payout Payout, fromStatus string) (Payout, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Payout{}).Where("id = ? AND status = ?", payout.ID, fromStatus).Updates(map[string]interface{}{
+			"status":       payout.Status,
+			"note":         payout.Note,
+			"processed_by": payout.ProcessedBy,
+			"processed_at": payout.ProcessedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrPayoutProcessed
+		}
+
+		if payout.Status != StatusPaid {
+			return nil
+		}
+
+		return ledger.PostJournal(tx, ledger.NewPayoutJournal(payout.ID, payout.CampaignID, payout.Amount))
+	})
+
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+func sumWithdrawn(db *gorm.DB, campaignID int) (int, error) {
+	var withdrawn int
+	err := db.Model(&Payout{}).Select("COALESCE(SUM(amount), 0)").Where("campaign_id = ? AND status IN ?", campaignID, withdrawnStatuses).Scan(&withdrawn).Error
+
+	if err != nil {
+		return withdrawn, err
+	}
+
+	return withdrawn, nil
+}
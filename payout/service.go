@@ -0,0 +1,242 @@
+package payout
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/transaction"
+	"cfa-backend/user"
+	"errors"
+	"time"
+)
+
+type Service interface {
+	SaveBankAccount(input CreateBankAccountInput) (BankAccount, error)
+	GetBankAccounts(userID int) ([]BankAccount, error)
+	GetBalance(input GetCampaignPayoutInput) (Balance, error)
+	GetCampaignPayouts(input GetCampaignPayoutInput) ([]Payout, error)
+	RequestPayout(inputURI GetCampaignPayoutInput, input CreatePayoutInput) (Payout, error)
+	GetPayoutsByStatus(status string) ([]Payout, error)
+	ApprovePayout(inputURI GetPayoutDetailInput, input ProcessPayoutInput) (Payout, error)
+	RejectPayout(inputURI GetPayoutDetailInput, input ProcessPayoutInput) (Payout, error)
+	MarkPayoutAsPaid(inputURI GetPayoutDetailInput, input ProcessPayoutInput) (Payout, error)
+}
+
+type service struct {
+	repository            Repository
+	campaignRepository    campaign.Repository
+	transactionRepository transaction.Repository
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository, transactionRepository transaction.Repository) *service {
+	return &service{
+		repository:            repository,
+		campaignRepository:    campaignRepository,
+		transactionRepository: transactionRepository,
+	}
+}
+
+func (s *service) SaveBankAccount(input CreateBankAccountInput) (BankAccount, error) {
+	bankAccount := BankAccount{
+		UserID:        input.User.ID,
+		BankName:      input.BankName,
+		AccountNumber: input.AccountNumber,
+		AccountName:   input.AccountName,
+	}
+
+	newBankAccount, err := s.repository.SaveBankAccount(bankAccount)
+	if err != nil {
+		return newBankAccount, err
+	}
+
+	return newBankAccount, nil
+}
+
+func (s *service) GetBankAccounts(userID int) ([]BankAccount, error) {
+	bankAccounts, err := s.repository.FindBankAccountsByUserID(userID)
+	if err != nil {
+		return bankAccounts, err
+	}
+
+	return bankAccounts, nil
+}
+
+func (s *service) GetBalance(input GetCampaignPayoutInput) (Balance, error) {
+	_, err := s.findManagedCampaign(input.ID, input.User)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	return s.balance(input.ID)
+}
+
+func (s *service) GetCampaignPayouts(input GetCampaignPayoutInput) ([]Payout, error) {
+	_, err := s.findManagedCampaign(input.ID, input.User)
+	if err != nil {
+		return []Payout{}, err
+	}
+
+	payouts, err := s.repository.FindByCampaignID(input.ID)
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+// RequestPayout lets a campaign owner withdraw up to the available balance of
+// the campaign into one of their own bank accounts.
+func (s *service) RequestPayout(inputURI GetCampaignPayoutInput, input CreatePayoutInput) (Payout, error) {
+	campaign, err := s.campaignRepository.FindByID(inputURI.ID)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	if campaign.ID == 0 {
+		return Payout{}, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != input.User.ID {
+		return Payout{}, errors.New("You do not have authorization to request a payout for the campaign!")
+	}
+
+	if !campaign.FundsReleasable() {
+		return Payout{}, errors.New("Donations of an all-or-nothing campaign are held until it reaches its goal at the deadline!")
+	}
+
+	bankAccount, err := s.repository.FindBankAccountByID(input.BankAccountID)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	if bankAccount.ID == 0 || bankAccount.UserID != input.User.ID {
+		return Payout{}, errors.New("No bank account found with that ID")
+	}
+
+	if input.Amount <= 0 {
+		return Payout{}, errors.New("Payout amount must be greater than zero!")
+	}
+
+	payout := Payout{
+		CampaignID:    campaign.ID,
+		UserID:        input.User.ID,
+		BankAccountID: bankAccount.ID,
+		Amount:        input.Amount,
+		Status:        StatusRequested,
+	}
+
+	newPayout, err := s.repository.SaveWithinBalance(payout, s.transactionRepository)
+	if err != nil {
+		return newPayout, err
+	}
+
+	newPayout.BankAccount = bankAccount
+
+	return newPayout, nil
+}
+
+func (s *service) GetPayoutsByStatus(status string) ([]Payout, error) {
+	if status == "" {
+		status = StatusRequested
+	}
+
+	payouts, err := s.repository.FindByStatus(status)
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+func (s *service) ApprovePayout(inputURI GetPayoutDetailInput, input ProcessPayoutInput) (Payout, error) {
+	return s.process(inputURI.ID, input, StatusRequested, StatusApproved)
+}
+
+func (s *service) RejectPayout(inputURI GetPayoutDetailInput, input ProcessPayoutInput) (Payout, error) {
+	payout, err := s.repository.FindByID(inputURI.ID)
+	if err != nil {
+		return payout, err
+	}
+
+	return s.process(inputURI.ID, input, payout.Status, StatusRejected)
+}
+
+// MarkPayoutAsPaid records that an approved payout has been transferred. The
+// repository moves the money out of the campaign account in the ledger along
+// with the status.
+func (s *service) MarkPayoutAsPaid(inputURI GetPayoutDetailInput, input ProcessPayoutInput) (Payout, error) {
+	return s.process(inputURI.ID, input, StatusApproved, StatusPaid)
+}
+
+// process moves a payout from one status to another on behalf of an admin.
+// Requested and approved payouts can be rejected; everything else only moves
+// forward one step.
+func (s *service) process(ID int, input ProcessPayoutInput, fromStatus string, toStatus string) (Payout, error) {
+	if input.User.Role != user.RoleAdmin {
+		return Payout{}, errors.New("You do not have authorization to process payouts!")
+	}
+
+	payout, err := s.repository.FindByID(ID)
+	if err != nil {
+		return payout, err
+	}
+
+	if payout.ID == 0 {
+		return payout, errors.New("No payout found with that ID")
+	}
+
+	if payout.Status != fromStatus || (fromStatus != StatusRequested && fromStatus != StatusApproved) {
+		return payout, errors.New("Payout cannot be " + toStatus + " from status " + payout.Status + "!")
+	}
+
+	now := time.Now()
+	payout.Status = toStatus
+	payout.Note = input.Note
+	payout.ProcessedBy = input.User.ID
+	payout.ProcessedAt = &now
+
+	updatedPayout, err := s.repository.Process(payout, fromStatus)
+	if err != nil {
+		return updatedPayout, err
+	}
+
+	return updatedPayout, nil
+}
+
+func (s *service) balance(campaignID int) (Balance, error) {
+	settled, err := s.transactionRepository.SumSettledByCampaignID(campaignID)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	withdrawn, err := s.repository.SumWithdrawnByCampaignID(campaignID)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	balance := Balance{
+		CampaignID: campaignID,
+		Settled:    settled.Amount,
+		Fees:       settled.Fees,
+		Withdrawn:  withdrawn,
+		Available:  settled.Amount - settled.Fees - withdrawn,
+	}
+
+	return balance, nil
+}
+
+// findManagedCampaign returns the campaign if the user owns it or is an admin.
+func (s *service) findManagedCampaign(campaignID int, currentUser user.User) (campaign.Campaign, error) {
+	campaign, err := s.campaignRepository.FindByID(campaignID)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		return campaign, errors.New("No campaign found with that ID")
+	}
+
+	if campaign.UserID != currentUser.ID && currentUser.Role != user.RoleAdmin {
+		return campaign, errors.New("You do not have authorization to manage the campaign payouts!")
+	}
+
+	return campaign, nil
+}
@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestWriteToCrossReference reads a written document back through its
+// cross-reference table, the way a viewer does: every offset has to point at
+// the start of its object and startxref at the table itself.
+func TestWriteToCrossReference(t *testing.T) {
+	document := New()
+	document.Text(50, 60, FontBold, 18, "Donation receipt")
+	document.Line(50, 70, 545, 70, 1)
+	document.AddPage()
+	document.Rect(50, 100, 20, 20, 0)
+
+	var out bytes.Buffer
+	n, err := document.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := out.Bytes()
+	if n != int64(len(content)) {
+		t.Errorf("WriteTo() = %d, wrote %d bytes", n, len(content))
+	}
+
+	if !bytes.HasPrefix(content, []byte("%PDF-1.4\n")) {
+		t.Fatalf("document does not start with a PDF header")
+	}
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(content)
+	if match == nil {
+		t.Fatalf("document does not end with startxref")
+	}
+
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(content[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the cross-reference table", xref)
+	}
+
+	lines := strings.Split(string(content[xref:]), "\n")
+	var first, count int
+	fmt.Sscanf(lines[1], "%d %d", &first, &count)
+
+	// Two pages take the catalog, the page tree, two fonts and two objects
+	// per page.
+	if first != 0 || count != 9 {
+		t.Fatalf("cross-reference table covers objects %d to %d, want 0 to 8", first, first+count-1)
+	}
+
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("entry of object 0 = %q, want the free list head", lines[2])
+	}
+
+	for object := 1; object < count; object++ {
+		entry := lines[2+object]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Errorf("entry of object %d = %q, want 20 bytes in use", object, entry)
+			continue
+		}
+
+		offset, _ := strconv.Atoi(entry[:10])
+		header := fmt.Sprintf("%d 0 obj\n", object)
+		if !bytes.HasPrefix(content[offset:], []byte(header)) {
+			t.Errorf("offset %d of object %d does not point at %q", offset, object, header)
+		}
+	}
+
+	if !strings.Contains(string(content[xref:]), "<< /Size 9 /Root 1 0 R >>") {
+		t.Errorf("trailer does not give the size and root of the document")
+	}
+}
+
+func TestWriteToContentStream(t *testing.T) {
+	document := New()
+	document.Text(50, 60, FontRegular, 12, "Total (IDR)")
+
+	var out bytes.Buffer
+	_, err := document.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := regexp.MustCompile(`(?s)<< /Length (\d+) /Filter /FlateDecode >>\nstream\n(.*)\nendstream`).FindSubmatch(out.Bytes())
+	if match == nil {
+		t.Fatalf("document has no content stream")
+	}
+
+	length, _ := strconv.Atoi(string(match[1]))
+	if length != len(match[2]) {
+		t.Errorf("stream /Length = %d, stream has %d bytes", length, len(match[2]))
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(match[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "BT /F1 12.00 Tf 50.00 781.89 Td (Total \\(IDR\\)) Tj ET\n"
+	if string(stream) != want {
+		t.Errorf("content stream = %q, want %q", stream, want)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Receipt", "Receipt"},
+		{`a (b) \c`, `a \(b\) \\c`},
+		{"line\nbreak", "line break"},
+		{"Rp 1.000 ©", `Rp 1.000 \251`},
+		{"Café", `Caf\351`},
+		{"€ 5 ✓", "? 5 ?"},
+	}
+
+	for _, test := range tests {
+		got := escape(test.text)
+		if got != test.want {
+			t.Errorf("escape(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package qrcode
+
+type matrix struct {
+	size       int
+	version    int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// newMatrix draws the function patterns of a version: finder, timing and
+// alignment patterns, and reserves the format and version information areas.
+func newMatrix(version int) *matrix {
+	size := version*4 + 17
+	m := &matrix{size: size, version: version}
+
+	m.modules = make([][]bool, size)
+	m.isFunction = make([][]bool, size)
+	for i := range m.modules {
+		m.modules[i] = make([]bool, size)
+		m.isFunction[i] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(size-4, 3)
+	m.drawFinder(3, size-4)
+
+	positions := versions[version-1].alignment
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(x, y)
+		}
+	}
+
+	m.drawFormat(0)
+	m.drawVersion()
+
+	return m
+}
+
+func (m *matrix) setFunction(x int, y int, dark bool) {
+	m.modules[y][x] = dark
+	m.isFunction[y][x] = true
+}
+
+// drawFinder draws a finder pattern centered on x, y with its light separator.
+func (m *matrix) drawFinder(x int, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= m.size || yy >= m.size {
+				continue
+			}
+
+			distance := max(abs(dx), abs(dy))
+			m.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (m *matrix) drawAlignment(x int, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for level M and the
+// given mask, plus the dark module.
+func (m *matrix) drawFormat(mask int) {
+	// Level M is encoded as 00, so the data is just the mask.
+	data := mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+
+	bit := func(i int) bool {
+		return (bits>>i)&1 == 1
+	}
+
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(i))
+	}
+	m.setFunction(8, 7, bit(6))
+	m.setFunction(8, 8, bit(7))
+	m.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(i))
+	}
+
+	m.setFunction(8, m.size-8, true)
+}
+
+// drawVersion draws both copies of the version information, which only
+// versions 7 and up carry.
+func (m *matrix) drawVersion() {
+	if m.version < 7 {
+		return
+	}
+
+	remainder := m.version
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | remainder
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a := m.size - 11 + i%3
+		b := i / 3
+
+		m.setFunction(a, b, dark)
+		m.setFunction(b, a, dark)
+	}
+}
+
+// placeData fills the non-function modules with the codewords in the zigzag
+// order of the specification, two columns at a time from the bottom right.
+func (m *matrix) placeData(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for vertical := 0; vertical < m.size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = m.size - 1 - vertical
+				}
+
+				if m.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+
+				m.modules[y][x] = (codewords[i/8]>>(7-i%8))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.isFunction[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores the matrix with the four rules of the specification; the
+// mask with the lowest score is the easiest to read.
+func (m *matrix) penalty() int {
+	result := 0
+
+	for _, horizontal := range []bool{true, false} {
+		at := func(a int, b int) bool {
+			if horizontal {
+				return m.modules[a][b]
+			}
+			return m.modules[b][a]
+		}
+
+		for a := 0; a < m.size; a++ {
+			run := 1
+			for b := 1; b < m.size; b++ {
+				if at(a, b) == at(a, b-1) {
+					run++
+					continue
+				}
+
+				if run >= 5 {
+					result += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				result += run - 2
+			}
+
+			for b := 0; b+11 <= m.size; b++ {
+				for _, pattern := range finderLike {
+					matches := true
+					for k, dark := range pattern {
+						if at(a, b+k) != dark {
+							matches = false
+							break
+						}
+					}
+
+					if matches {
+						result += 40
+					}
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < m.size && y+1 < m.size {
+				color := m.modules[y][x]
+				if m.modules[y][x+1] == color && m.modules[y+1][x] == color && m.modules[y+1][x+1] == color {
+					result += 3
+				}
+			}
+		}
+	}
+
+	total := m.size * m.size
+	result += abs(dark*100/total-50) / 5 * 10
+
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,194 @@
+// Package qrcode encodes short texts, such as verification URLs, as QR codes.
+// It supports byte mode at error correction level M for versions 1 to 10,
+// which holds up to 213 bytes.
+package qrcode
+
+import "errors"
+
+var ErrDataTooLong = errors.New("Data is too long for a QR code!")
+
+// Code is an encoded QR code. Modules are indexed by row, then column.
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x and row y is dark. Modules
+// outside the code are light, which makes up the quiet zone.
+func (c *Code) Dark(x int, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+
+	return c.modules[y][x]
+}
+
+// blockGroup is a run of error correction blocks of the same data length.
+type blockGroup struct {
+	count     int
+	dataWords int
+}
+
+type versionInfo struct {
+	ecWords   int
+	groups    []blockGroup
+	alignment []int
+}
+
+// versions holds the level M block structure and alignment pattern centers
+// of versions 1 to 10, indexed by version - 1.
+var versions = []versionInfo{
+	{10, []blockGroup{{1, 16}}, nil},
+	{16, []blockGroup{{1, 28}}, []int{6, 18}},
+	{26, []blockGroup{{1, 44}}, []int{6, 22}},
+	{18, []blockGroup{{2, 32}}, []int{6, 26}},
+	{24, []blockGroup{{2, 43}}, []int{6, 30}},
+	{16, []blockGroup{{4, 27}}, []int{6, 34}},
+	{18, []blockGroup{{4, 31}}, []int{6, 22, 38}},
+	{22, []blockGroup{{2, 38}, {2, 39}}, []int{6, 24, 42}},
+	{22, []blockGroup{{3, 36}, {2, 37}}, []int{6, 26, 46}},
+	{26, []blockGroup{{4, 43}, {1, 44}}, []int{6, 28, 50}},
+}
+
+func (v versionInfo) dataWords() int {
+	total := 0
+	for _, group := range v.groups {
+		total += group.count * group.dataWords
+	}
+
+	return total
+}
+
+// Encode encodes data in the smallest version it fits in, choosing the mask
+// that scores the lowest penalty.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+	for i, info := range versions {
+		countBits := 8
+		if i+1 >= 10 {
+			countBits = 16
+		}
+
+		if 4+countBits+len(data)*8 <= info.dataWords()*8 {
+			version = i + 1
+			break
+		}
+	}
+
+	if version == 0 {
+		return nil, ErrDataTooLong
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version), versions[version-1])
+
+	var best *Code
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		m := newMatrix(version)
+		m.placeData(codewords)
+		m.applyMask(mask)
+		m.drawFormat(mask)
+
+		penalty := m.penalty()
+		if best == nil || penalty < bestPenalty {
+			best = &Code{Size: m.size, modules: m.modules}
+			bestPenalty = penalty
+		}
+	}
+
+	return best, nil
+}
+
+// encodeData builds the data codewords: byte mode indicator, character count,
+// the data itself, a terminator and padding.
+func encodeData(data []byte, version int) []byte {
+	capacity := versions[version-1].dataWords()
+	bits := &bitBuffer{}
+
+	bits.append(0x4, 4)
+	if version >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	terminator := capacity*8 - bits.length
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-bits.length%8)%8)
+
+	codewords := bits.bytes()
+	for pad := 0; len(codewords) < capacity; pad++ {
+		if pad%2 == 0 {
+			codewords = append(codewords, 0xEC)
+		} else {
+			codewords = append(codewords, 0x11)
+		}
+	}
+
+	return codewords
+}
+
+// addErrorCorrection splits the data into blocks, computes the Reed-Solomon
+// codewords of each block and interleaves everything in transmission order.
+func addErrorCorrection(data []byte, info versionInfo) []byte {
+	var dataBlocks, ecBlocks [][]byte
+	generator := rsGenerator(info.ecWords)
+
+	offset := 0
+	for _, group := range info.groups {
+		for i := 0; i < group.count; i++ {
+			block := data[offset : offset+group.dataWords]
+			offset += group.dataWords
+
+			dataBlocks = append(dataBlocks, block)
+			ecBlocks = append(ecBlocks, rsRemainder(block, generator))
+		}
+	}
+
+	var result []byte
+	longest := info.groups[len(info.groups)-1].dataWords
+	for i := 0; i < longest; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	for i := 0; i < info.ecWords; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+type bitBuffer struct {
+	data   []byte
+	length int
+}
+
+func (b *bitBuffer) append(value int, count int) {
+	for i := count - 1; i >= 0; i-- {
+		if b.length%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+
+		if (value>>i)&1 == 1 {
+			b.data[b.length/8] |= 0x80 >> (b.length % 8)
+		}
+		b.length++
+	}
+}
+
+func (b *bitBuffer) bytes() []byte {
+	return b.data
+}
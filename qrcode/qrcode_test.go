@@ -0,0 +1,126 @@
+package qrcode
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The golden matrices in testdata were produced by an independent encoder for
+// the same data, mask and level M. Dark modules are # and light ones are dots.
+var goldenTests = []struct {
+	file    string
+	data    string
+	version int
+	mask    int
+}{
+	{"hello_mask2.txt", "hello", 1, 2},
+	{"short_url_mask6.txt", "https://cfa.example/receipt", 3, 6},
+	{"version7_mask4.txt", "https://cfa.example/api/v-one/receipts/verify?code=cfa-abcdefg-hjkmnpq&verification_code=deadbeefcafebabefeedfacebadcodes", 7, 4},
+	{"version10_mask2.txt", "https://cfa.example/api/v-one/campaigns/the-quick-brown-fox-jumps-over-the-lazy-dog/updates?from=the-first-update&to=the-last-update&sort=oldest&include=images&note=thank-you-to-every-backer-out-there", 10, 2},
+}
+
+func TestMatrixGolden(t *testing.T) {
+	for _, test := range goldenTests {
+		t.Run(test.file, func(t *testing.T) {
+			want := readGolden(t, test.file)
+
+			codewords := addErrorCorrection(encodeData([]byte(test.data), test.version), versions[test.version-1])
+			m := newMatrix(test.version)
+			m.placeData(codewords)
+			m.applyMask(test.mask)
+			m.drawFormat(test.mask)
+
+			if len(want) != m.size {
+				t.Fatalf("golden has %d rows, matrix is %d modules wide", len(want), m.size)
+			}
+
+			for y, row := range want {
+				for x, module := range row {
+					if m.modules[y][x] != (module == '#') {
+						t.Errorf("module at column %d, row %d is dark = %t, want %c", x, y, m.modules[y][x], module)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeChoosesSmallestVersion(t *testing.T) {
+	for _, test := range goldenTests {
+		code, err := Encode([]byte(test.data))
+		if err != nil {
+			t.Fatalf("Encode(%q) error = %v", test.data, err)
+		}
+
+		if want := test.version*4 + 17; code.Size != want {
+			t.Errorf("Encode(%q) size = %d, want %d", test.data, code.Size, want)
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	_, err := Encode([]byte(strings.Repeat("a", 213)))
+	if err != nil {
+		t.Fatalf("Encode(213 bytes) error = %v", err)
+	}
+
+	_, err = Encode([]byte(strings.Repeat("a", 214)))
+	if !errors.Is(err, ErrDataTooLong) {
+		t.Errorf("Encode(214 bytes) error = %v, want %v", err, ErrDataTooLong)
+	}
+}
+
+// TestFormatInformation checks the format bits drawn next to the top left
+// finder pattern against the level M rows of the specification's table.
+func TestFormatInformation(t *testing.T) {
+	want := []string{
+		"101010000010010",
+		"101000100100101",
+		"101111001111100",
+		"101101101001011",
+		"100010111111001",
+		"100000011001110",
+		"100111110010111",
+		"100101010100000",
+	}
+
+	for mask, bits := range want {
+		m := newMatrix(1)
+		m.drawFormat(mask)
+
+		// Bits 14 to 9 run along row 8, skipping the timing column.
+		var got strings.Builder
+		for _, x := range []int{0, 1, 2, 3, 4, 5, 7, 8} {
+			got.WriteString(bit(m.modules[8][x]))
+		}
+		for _, y := range []int{7, 5, 4, 3, 2, 1, 0} {
+			got.WriteString(bit(m.modules[y][8]))
+		}
+
+		if got.String() != bits {
+			t.Errorf("format information of mask %d = %s, want %s", mask, got.String(), bits)
+		}
+	}
+}
+
+func readGolden(t *testing.T, file string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func bit(dark bool) string {
+	if dark {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,60 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{
+			// The worked example of the specification: 01234567 in
+			// numeric mode at version 1-M.
+			name: "specification example",
+			data: []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11},
+			want: []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55},
+		},
+		{
+			// HELLO WORLD in alphanumeric mode at version 1-M.
+			name: "hello world",
+			data: []byte{0x20, 0x5B, 0x0B, 0x78, 0xD1, 0x72, 0xDC, 0x4D, 0x43, 0x40, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11},
+			want: []byte{0xC4, 0x23, 0x27, 0x77, 0xEB, 0xD7, 0xE7, 0xE2, 0x5D, 0x17},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := rsRemainder(test.data, rsGenerator(len(test.want)))
+			if !bytes.Equal(got, test.want) {
+				t.Errorf("rsRemainder() = % X, want % X", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGFMultiply(t *testing.T) {
+	tests := []struct {
+		x, y, want byte
+	}{
+		{0x00, 0x53, 0x00},
+		{0x01, 0x53, 0x53},
+		{0x02, 0x80, 0x1D},
+		{0x80, 0x80, 0x13},
+		{0x53, 0xCA, 0x8F},
+	}
+
+	for _, test := range tests {
+		got := gfMultiply(test.x, test.y)
+		if got != test.want {
+			t.Errorf("gfMultiply(%#02x, %#02x) = %#02x, want %#02x", test.x, test.y, got, test.want)
+		}
+
+		if reverse := gfMultiply(test.y, test.x); reverse != got {
+			t.Errorf("gfMultiply(%#02x, %#02x) = %#02x, not commutative", test.y, test.x, reverse)
+		}
+	}
+}
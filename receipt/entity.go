@@ -0,0 +1,21 @@
+package receipt
+
+import "cfa-backend/transaction"
+
+// Organization is the issuer printed on every receipt.
+type Organization struct {
+	Name    string
+	Address string
+	Email   string
+	TaxID   string
+}
+
+// Receipt is the official proof of a paid donation. VerificationCode is a
+// signature of the transaction code; the QR code on the receipt links to
+// VerificationURL, which checks it.
+type Receipt struct {
+	Transaction      transaction.Transaction
+	DonorName        string
+	VerificationCode string
+	VerificationURL  string
+}
@@ -0,0 +1,28 @@
+package receipt
+
+import "time"
+
+type ReceiptVerificationFormatter struct {
+	Valid          bool      `json:"valid"`
+	Code           string    `json:"code"`
+	CampaignName   string    `json:"campaign_name"`
+	Amount         int       `json:"amount"`
+	RefundedAmount int       `json:"refunded_amount"`
+	Status         string    `json:"status"`
+	DonatedAt      time.Time `json:"donated_at"`
+}
+
+func FormatReceiptVerification(receipt Receipt) ReceiptVerificationFormatter {
+	donation := receipt.Transaction
+
+	formatter := ReceiptVerificationFormatter{}
+	formatter.Valid = true
+	formatter.Code = donation.Code
+	formatter.CampaignName = donation.Campaign.Name
+	formatter.Amount = donation.Amount
+	formatter.RefundedAmount = donation.RefundedAmount
+	formatter.Status = donation.Status
+	formatter.DonatedAt = donation.CreatedAt
+
+	return formatter
+}
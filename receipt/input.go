@@ -0,0 +1,18 @@
+package receipt
+
+import "cfa-backend/user"
+
+type GetReceiptInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type GetGuestReceiptInput struct {
+	Code  string `uri:"code" binding:"required"`
+	Token string `form:"token" binding:"required"`
+}
+
+type VerifyReceiptInput struct {
+	Code             string `form:"code" binding:"required"`
+	VerificationCode string `form:"verification_code" binding:"required"`
+}
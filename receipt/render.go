@@ -0,0 +1,97 @@
+package receipt
+
+import (
+	"cfa-backend/helper"
+	"cfa-backend/pdf"
+	"cfa-backend/qrcode"
+	"strings"
+)
+
+const (
+	marginLeft = 50.0
+	qrSize     = 110.0
+	qrTop      = 40.0
+)
+
+func render(receipt Receipt, organization Organization) (*pdf.Document, error) {
+	code, err := qrcode.Encode([]byte(receipt.VerificationURL))
+	if err != nil {
+		return nil, err
+	}
+
+	donation := receipt.Transaction
+	document := pdf.New()
+	document.AddPage()
+
+	y := 70.0
+	document.Text(marginLeft, y, pdf.FontBold, 18, organization.Name)
+	for _, line := range []string{organization.Address, organization.Email, taxIDLine(organization.TaxID)} {
+		if line == "" {
+			continue
+		}
+
+		y += 14
+		document.Text(marginLeft, y, pdf.FontRegular, 10, line)
+	}
+
+	qrX := pdf.PageWidth - marginLeft - qrSize
+	drawQRCode(document, code, qrX, qrTop, qrSize)
+	document.Text(qrX+8, qrTop+qrSize+8, pdf.FontRegular, 8, "Scan to verify this receipt")
+
+	y = 210
+	document.Text(marginLeft, y, pdf.FontBold, 16, "DONATION RECEIPT")
+	document.Line(marginLeft, y+10, pdf.PageWidth-marginLeft, y+10, 1)
+
+	rows := [][2]string{
+		{"Receipt number", donation.Code},
+		{"Date", donation.CreatedAt.Format("2 January 2006 15:04")},
+		{"Donor", receipt.DonorName},
+		{"Campaign", donation.Campaign.Name},
+		{"Payment method", strings.ReplaceAll(donation.PaymentMethod, "_", " ")},
+		{"Amount", helper.FormatRupiah(donation.Amount)},
+	}
+	if donation.RefundedAmount > 0 {
+		rows = append(rows, [2]string{"Refunded", helper.FormatRupiah(donation.RefundedAmount)})
+	}
+
+	y += 40
+	for _, row := range rows {
+		if row[1] == "" {
+			row[1] = "-"
+		}
+
+		document.Text(marginLeft, y, pdf.FontRegular, 11, row[0])
+		document.Text(marginLeft+110, y, pdf.FontBold, 11, row[1])
+		y += 22
+	}
+
+	y += 30
+	document.Line(marginLeft, y, pdf.PageWidth-marginLeft, y, 0.5)
+	document.Text(marginLeft, y+20, pdf.FontRegular, 9, "Thank you for your support. This receipt was issued electronically and is valid without a signature.")
+	document.Text(marginLeft, y+34, pdf.FontRegular, 9, "Verification code: "+receipt.VerificationCode)
+
+	return document, nil
+}
+
+// drawQRCode draws a QR code with its quiet zone inside a square of the given
+// size.
+func drawQRCode(document *pdf.Document, code *qrcode.Code, x float64, y float64, size float64) {
+	const quietZone = 4
+
+	module := size / float64(code.Size+quietZone*2)
+	for row := 0; row < code.Size; row++ {
+		for column := 0; column < code.Size; column++ {
+			if code.Dark(column, row) {
+				document.Rect(x+float64(column+quietZone)*module, y+float64(row+quietZone)*module, module, module, 0)
+			}
+		}
+	}
+}
+
+func taxIDLine(taxID string) string {
+	if taxID == "" {
+		return ""
+	}
+
+	return "Tax ID: " + taxID
+}
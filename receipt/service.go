@@ -0,0 +1,159 @@
+package receipt
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/guest"
+	"cfa-backend/transaction"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+)
+
+var (
+	ErrReceiptNotFound = errors.New("No receipt found with that ID")
+	ErrInvalidReceipt  = errors.New("Receipt could not be verified!")
+)
+
+type Service interface {
+	GetReceipt(input GetReceiptInput) (Receipt, error)
+	GetGuestReceipt(input GetGuestReceiptInput) (Receipt, error)
+	VerifyReceipt(input VerifyReceiptInput) (Receipt, error)
+	RenderPDF(receipt Receipt, w io.Writer) error
+}
+
+type service struct {
+	transactionRepository transaction.Repository
+	campaignRepository    campaign.Repository
+	guestService          guest.Service
+	organization          Organization
+	appURL                string
+	secret                []byte
+}
+
+func NewService(transactionRepository transaction.Repository, campaignRepository campaign.Repository, guestService guest.Service, organization Organization, appURL string, secret string) *service {
+	return &service{
+		transactionRepository: transactionRepository,
+		campaignRepository:    campaignRepository,
+		guestService:          guestService,
+		organization:          organization,
+		appURL:                appURL,
+		secret:                []byte(secret),
+	}
+}
+
+// GetReceipt returns the receipt of one of the user's own paid donations.
+func (s *service) GetReceipt(input GetReceiptInput) (Receipt, error) {
+	donation, err := s.transactionRepository.FindByID(input.ID)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	if donation.ID == 0 || donation.UserID != input.User.ID {
+		return Receipt{}, ErrReceiptNotFound
+	}
+
+	if donation.Status != transaction.StatusPaid && donation.Status != transaction.StatusSettled {
+		return Receipt{}, errors.New("Receipts are only available for paid donations!")
+	}
+
+	return s.newReceipt(donation, input.User.Name), nil
+}
+
+// GetGuestReceipt returns the receipt of a paid guest donation to whoever has
+// the receipt link emailed to the guest.
+func (s *service) GetGuestReceipt(input GetGuestReceiptInput) (Receipt, error) {
+	err := s.guestService.ValidateReceiptToken(input.Code, input.Token)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	donation, err := s.transactionRepository.FindByCode(input.Code)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	if donation.ID == 0 || donation.GuestID == 0 {
+		return Receipt{}, ErrReceiptNotFound
+	}
+
+	if donation.Status != transaction.StatusPaid && donation.Status != transaction.StatusSettled {
+		return Receipt{}, errors.New("Receipts are only available for paid donations!")
+	}
+
+	donor, err := s.guestService.GetGuestByID(donation.GuestID)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	campaign, err := s.campaignRepository.FindByID(donation.CampaignID)
+	if err != nil {
+		return Receipt{}, err
+	}
+	donation.Campaign = campaign
+
+	return s.newReceipt(donation, donor.Name), nil
+}
+
+// VerifyReceipt checks the verification code scanned from a receipt and
+// returns the receipt's donation as it stands now, so a refunded donation shows
+// up as such.
+func (s *service) VerifyReceipt(input VerifyReceiptInput) (Receipt, error) {
+	expected := s.verificationCode(input.Code)
+	if !hmac.Equal([]byte(expected), []byte(input.VerificationCode)) {
+		return Receipt{}, ErrInvalidReceipt
+	}
+
+	donation, err := s.transactionRepository.FindByCode(input.Code)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	if donation.ID == 0 {
+		return Receipt{}, ErrInvalidReceipt
+	}
+
+	campaign, err := s.campaignRepository.FindByID(donation.CampaignID)
+	if err != nil {
+		return Receipt{}, err
+	}
+	donation.Campaign = campaign
+
+	return s.newReceipt(donation, ""), nil
+}
+
+func (s *service) RenderPDF(receipt Receipt, w io.Writer) error {
+	document, err := render(receipt, s.organization)
+	if err != nil {
+		return err
+	}
+
+	_, err = document.WriteTo(w)
+	return err
+}
+
+func (s *service) newReceipt(donation transaction.Transaction, donorName string) Receipt {
+	code := s.verificationCode(donation.Code)
+
+	query := url.Values{}
+	query.Set("code", donation.Code)
+	query.Set("verification_code", code)
+
+	return Receipt{
+		Transaction:      donation,
+		DonorName:        donorName,
+		VerificationCode: code,
+		VerificationURL:  s.appURL + "/api/v1/receipts/verify?" + query.Encode(),
+	}
+}
+
+// verificationCode signs a transaction code. It is kept short so that the
+// verification URL fits a small QR code.
+func (s *service) verificationCode(code string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(code))
+
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
@@ -0,0 +1,27 @@
+package reconciliation
+
+import "time"
+
+// CampaignTotals compares the totals stored on a campaign with the totals
+// expected from its paid transactions.
+type CampaignTotals struct {
+	CampaignID      int
+	Name            string
+	StoredAmount    int
+	StoredBackers   int
+	ExpectedAmount  int
+	ExpectedBackers int
+}
+
+type Mismatch struct {
+	CampaignTotals
+	Fixed bool
+	Error string
+}
+
+type Report struct {
+	GeneratedAt      time.Time
+	DryRun           bool
+	CampaignsChecked int
+	Mismatches       []Mismatch
+}
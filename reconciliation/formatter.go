@@ -0,0 +1,50 @@
+package reconciliation
+
+import "time"
+
+type ReportFormatter struct {
+	GeneratedAt      time.Time           `json:"generated_at"`
+	DryRun           bool                `json:"dry_run"`
+	CampaignsChecked int                 `json:"campaigns_checked"`
+	MismatchCount    int                 `json:"mismatch_count"`
+	Mismatches       []MismatchFormatter `json:"mismatches"`
+}
+
+type MismatchFormatter struct {
+	CampaignID      int    `json:"campaign_id"`
+	Name            string `json:"name"`
+	StoredAmount    int    `json:"stored_amount"`
+	ExpectedAmount  int    `json:"expected_amount"`
+	StoredBackers   int    `json:"stored_backers"`
+	ExpectedBackers int    `json:"expected_backers"`
+	Fixed           bool   `json:"fixed"`
+	Error           string `json:"error,omitempty"`
+}
+
+func FormatReport(report Report) ReportFormatter {
+	formatter := ReportFormatter{
+		GeneratedAt:      report.GeneratedAt,
+		DryRun:           report.DryRun,
+		CampaignsChecked: report.CampaignsChecked,
+		MismatchCount:    len(report.Mismatches),
+	}
+
+	mismatchesFormatter := []MismatchFormatter{}
+	for _, mismatch := range report.Mismatches {
+		mismatchFormatter := MismatchFormatter{
+			CampaignID:      mismatch.CampaignID,
+			Name:            mismatch.Name,
+			StoredAmount:    mismatch.StoredAmount,
+			ExpectedAmount:  mismatch.ExpectedAmount,
+			StoredBackers:   mismatch.StoredBackers,
+			ExpectedBackers: mismatch.ExpectedBackers,
+			Fixed:           mismatch.Fixed,
+			Error:           mismatch.Error,
+		}
+		mismatchesFormatter = append(mismatchesFormatter, mismatchFormatter)
+	}
+
+	formatter.Mismatches = mismatchesFormatter
+
+	return formatter
+}
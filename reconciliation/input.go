@@ -0,0 +1,5 @@
+package reconciliation
+
+type ReconcileInput struct {
+	Fix bool `json:"fix"`
+}
@@ -0,0 +1,58 @@
+package reconciliation
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/transaction"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetCampaignTotals() ([]CampaignTotals, error)
+	FixCampaignTotals(totals CampaignTotals) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// GetCampaignTotals recomputes every campaign's totals from its paid and
+// settled transactions, net of partial refunds, next to the stored ones.
+func (r *repository) GetCampaignTotals() ([]CampaignTotals, error) {
+	var totals []CampaignTotals
+	err := r.db.Table("campaigns").
+		Select("campaigns.id AS campaign_id, campaigns.name, campaigns.current_amount AS stored_amount, campaigns.backer_count AS stored_backers, "+
+			"COALESCE(SUM(transactions.amount - transactions.refunded_amount), 0) AS expected_amount, COUNT(transactions.id) AS expected_backers").
+		Joins("LEFT JOIN transactions ON transactions.campaign_id = campaigns.id AND transactions.status IN ?", []string{transaction.StatusPaid, transaction.StatusSettled}).
+		Group("campaigns.id, campaigns.name, campaigns.current_amount, campaigns.backer_count").
+		Order("campaigns.id ASC").
+		Scan(&totals).Error
+
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
+
+// FixCampaignTotals overwrites the stored totals with the expected ones, but
+// only if the stored totals are still what they were when computed; a
+// donation settled in between makes it report false instead.
+func (r *repository) FixCampaignTotals(totals CampaignTotals) (bool, error) {
+	result := r.db.Model(&campaign.Campaign{}).
+		Where("id = ? AND current_amount = ? AND backer_count = ?", totals.CampaignID, totals.StoredAmount, totals.StoredBackers).
+		Updates(map[string]interface{}{
+			"current_amount": totals.ExpectedAmount,
+			"backer_count":   totals.ExpectedBackers,
+		})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,55 @@
+package reconciliation
+
+import "time"
+
+type Service interface {
+	Reconcile(fix bool) (Report, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *service {
+	return &service{repository: repository}
+}
+
+// Reconcile reports every campaign whose stored CurrentAmount or BackerCount
+// differs from its transactions. Without fix it is a dry run that changes
+// nothing.
+func (s *service) Reconcile(fix bool) (Report, error) {
+	report := Report{
+		GeneratedAt: time.Now(),
+		DryRun:      !fix,
+		Mismatches:  []Mismatch{},
+	}
+
+	campaignsTotals, err := s.repository.GetCampaignTotals()
+	if err != nil {
+		return report, err
+	}
+
+	report.CampaignsChecked = len(campaignsTotals)
+
+	for _, totals := range campaignsTotals {
+		if totals.StoredAmount == totals.ExpectedAmount && totals.StoredBackers == totals.ExpectedBackers {
+			continue
+		}
+
+		mismatch := Mismatch{CampaignTotals: totals}
+
+		if fix {
+			fixed, err := s.repository.FixCampaignTotals(totals)
+			if err != nil {
+				mismatch.Error = err.Error()
+			} else if !fixed {
+				mismatch.Error = "Campaign totals changed while reconciling, run again"
+			}
+			mismatch.Fixed = fixed
+		}
+
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	return report, nil
+}
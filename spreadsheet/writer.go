@@ -0,0 +1,45 @@
+package spreadsheet
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("Unsupported spreadsheet format!")
+
+// Writer writes a spreadsheet row by row, so large exports never have to be
+// held in memory. Cells may be strings, integers, booleans or nil. Close must
+// be called to finish the file.
+type Writer interface {
+	WriteRow(cells []interface{}) error
+	Close() error
+}
+
+// NewWriter returns a writer for the given format, writing to w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w)
+	}
+
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,24 @@
+package statement
+
+import (
+	"cfa-backend/user"
+	"time"
+)
+
+// CampaignTotal is what a user gave to one campaign during the year.
+type CampaignTotal struct {
+	CampaignID   int
+	CampaignName string
+	Donations    int
+	Amount       int
+}
+
+// Statement sums up the paid donations of a user in a calendar year, after
+// refunds, per campaign.
+type Statement struct {
+	Year        int
+	User        user.User
+	Campaigns   []CampaignTotal
+	Total       int
+	GeneratedAt time.Time
+}
@@ -0,0 +1,12 @@
+package statement
+
+import "cfa-backend/user"
+
+type GetStatementInput struct {
+	Year int `uri:"year" binding:"required,min=2000,max=9999"`
+	User user.User
+}
+
+type StatementFormatInput struct {
+	Format string `form:"format" binding:"omitempty,oneof=pdf csv"`
+}
@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"cfa-backend/helper"
+	"cfa-backend/pdf"
+	"cfa-backend/receipt"
+	"cfa-backend/spreadsheet"
+	"fmt"
+	"io"
+)
+
+const (
+	marginLeft    = 50.0
+	marginBottom  = 80.0
+	rowHeight     = 18.0
+	donationsLeft = 340.0
+	amountLeft    = 420.0
+)
+
+func renderPDF(statement Statement, organization receipt.Organization) *pdf.Document {
+	document := pdf.New()
+	document.AddPage()
+
+	document.Text(marginLeft, 70, pdf.FontBold, 18, organization.Name)
+	document.Text(marginLeft, 120, pdf.FontBold, 16, fmt.Sprintf("ANNUAL GIVING STATEMENT %d", statement.Year))
+	document.Text(marginLeft, 140, pdf.FontRegular, 10, "Donor: "+statement.User.Name+" <"+statement.User.Email+">")
+	document.Text(marginLeft, 154, pdf.FontRegular, 10, "Issued: "+statement.GeneratedAt.Format("2 January 2006"))
+
+	y := 190.0
+	header := func() {
+		document.Text(marginLeft, y, pdf.FontBold, 10, "Campaign")
+		document.Text(donationsLeft, y, pdf.FontBold, 10, "Donations")
+		document.Text(amountLeft, y, pdf.FontBold, 10, "Amount")
+		document.Line(marginLeft, y+6, pdf.PageWidth-marginLeft, y+6, 0.5)
+		y += rowHeight + 4
+	}
+	header()
+
+	if len(statement.Campaigns) == 0 {
+		document.Text(marginLeft, y, pdf.FontRegular, 10, fmt.Sprintf("No paid donations in %d.", statement.Year))
+		y += rowHeight
+	}
+
+	for _, campaign := range statement.Campaigns {
+		if y > pdf.PageHeight-marginBottom {
+			document.AddPage()
+			y = 70
+			header()
+		}
+
+		document.Text(marginLeft, y, pdf.FontRegular, 10, truncate(campaign.CampaignName, 50))
+		document.Text(donationsLeft, y, pdf.FontRegular, 10, fmt.Sprint(campaign.Donations))
+		document.Text(amountLeft, y, pdf.FontRegular, 10, helper.FormatRupiah(campaign.Amount))
+		y += rowHeight
+	}
+
+	document.Line(marginLeft, y-8, pdf.PageWidth-marginLeft, y-8, 0.5)
+	document.Text(marginLeft, y+6, pdf.FontBold, 11, "Total")
+	document.Text(amountLeft, y+6, pdf.FontBold, 11, helper.FormatRupiah(statement.Total))
+	document.Text(marginLeft, y+36, pdf.FontRegular, 9, "Amounts are paid donations after refunds. Individual receipts are available for every donation.")
+
+	return document
+}
+
+func renderCSV(statement Statement, w io.Writer) error {
+	writer := spreadsheet.NewCSVWriter(w)
+
+	rows := [][]interface{}{{"Campaign ID", "Campaign", "Donations", "Amount"}}
+	for _, campaign := range statement.Campaigns {
+		rows = append(rows, []interface{}{campaign.CampaignID, campaign.CampaignName, campaign.Donations, campaign.Amount})
+	}
+	rows = append(rows, []interface{}{nil, "Total", nil, statement.Total})
+
+	for _, row := range rows {
+		err := writer.WriteRow(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	return string(runes[:length-3]) + "..."
+}
@@ -0,0 +1,85 @@
+package statement
+
+import (
+	"cfa-backend/receipt"
+	"cfa-backend/transaction"
+	"errors"
+	"io"
+	"time"
+)
+
+type Service interface {
+	GetStatement(input GetStatementInput) (Statement, error)
+	RenderPDF(statement Statement, w io.Writer) error
+	RenderCSV(statement Statement, w io.Writer) error
+}
+
+type service struct {
+	transactionRepository transaction.Repository
+	organization          receipt.Organization
+}
+
+func NewService(transactionRepository transaction.Repository, organization receipt.Organization) *service {
+	return &service{transactionRepository: transactionRepository, organization: organization}
+}
+
+// GetStatement aggregates the user's paid donations made during the year per
+// campaign. Refunded amounts are left out, and fully refunded donations are
+// not counted at all.
+func (s *service) GetStatement(input GetStatementInput) (Statement, error) {
+	if input.Year > time.Now().Year() {
+		return Statement{}, errors.New("Statements are not available for future years!")
+	}
+
+	filter := transaction.TransactionFilter{
+		UserID: input.User.ID,
+		From:   time.Date(input.Year, time.January, 1, 0, 0, 0, 0, time.Local),
+		To:     time.Date(input.Year+1, time.January, 1, 0, 0, 0, 0, time.Local),
+	}
+
+	transactions, err := s.transactionRepository.GetTransactionByUserID(filter, transaction.PageQuery{Sort: transaction.SortOldest})
+	if err != nil {
+		return Statement{}, err
+	}
+
+	statement := Statement{Year: input.Year, User: input.User, GeneratedAt: time.Now()}
+	positions := map[int]int{}
+
+	// Transactions come oldest first, so campaigns are listed in the order the
+	// user first gave to them.
+	for _, donation := range transactions {
+		if donation.Status != transaction.StatusPaid && donation.Status != transaction.StatusSettled {
+			continue
+		}
+
+		amount := donation.Amount - donation.RefundedAmount
+		if amount <= 0 {
+			continue
+		}
+
+		position, ok := positions[donation.CampaignID]
+		if !ok {
+			position = len(statement.Campaigns)
+			positions[donation.CampaignID] = position
+			statement.Campaigns = append(statement.Campaigns, CampaignTotal{
+				CampaignID:   donation.CampaignID,
+				CampaignName: donation.Campaign.Name,
+			})
+		}
+
+		statement.Campaigns[position].Donations++
+		statement.Campaigns[position].Amount += amount
+		statement.Total += amount
+	}
+
+	return statement, nil
+}
+
+func (s *service) RenderPDF(statement Statement, w io.Writer) error {
+	_, err := renderPDF(statement, s.organization).WriteTo(w)
+	return err
+}
+
+func (s *service) RenderCSV(statement Statement, w io.Writer) error {
+	return renderCSV(statement, w)
+}
@@ -0,0 +1,35 @@
+package subscription
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/user"
+	"time"
+)
+
+const (
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
+)
+
+// Subscription is a monthly pledge. Every billing cycle a new transaction is
+// created for it on BillingDay of the month.
+type Subscription struct {
+	ID            int
+	UserID        int
+	CampaignID    int
+	Amount        int
+	Status        string
+	BillingDay    int
+	NextBillingAt time.Time
+	LastBilledAt  *time.Time
+	CancelledAt   *time.Time
+	// BillingAttempts counts the failed tries to bill the current cycle;
+	// the next try waits until NextBillingAttemptAt.
+	BillingAttempts      int
+	NextBillingAttemptAt *time.Time
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	User                 user.User
+	Campaign             campaign.Campaign
+}
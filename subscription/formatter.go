@@ -0,0 +1,66 @@
+package subscription
+
+import (
+	"cfa-backend/transaction"
+	"time"
+)
+
+type SubscriptionFormatter struct {
+	ID            int                               `json:"id"`
+	CampaignID    int                               `json:"campaign_id"`
+	Amount        int                               `json:"amount"`
+	Status        string                            `json:"status"`
+	BillingDay    int                               `json:"billing_day"`
+	NextBillingAt time.Time                         `json:"next_billing_at"`
+	LastBilledAt  *time.Time                        `json:"last_billed_at"`
+	CreatedAt     time.Time                         `json:"created_at"`
+	Campaign      SubscriptionCampaignFormatter     `json:"campaign"`
+	Transaction   *transaction.TransactionFormatter `json:"transaction,omitempty"`
+}
+
+type SubscriptionCampaignFormatter struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func FormatSubscription(subscription Subscription) SubscriptionFormatter {
+	formatter := SubscriptionFormatter{
+		ID:            subscription.ID,
+		CampaignID:    subscription.CampaignID,
+		Amount:        subscription.Amount,
+		Status:        subscription.Status,
+		BillingDay:    subscription.BillingDay,
+		NextBillingAt: subscription.NextBillingAt,
+		LastBilledAt:  subscription.LastBilledAt,
+		CreatedAt:     subscription.CreatedAt,
+	}
+
+	formatter.Campaign = SubscriptionCampaignFormatter{
+		Name: subscription.Campaign.Name,
+		Slug: subscription.Campaign.Slug,
+	}
+
+	return formatter
+}
+
+// FormatNewSubscription also includes the transaction of the first billing
+// cycle, which the backer still has to pay.
+func FormatNewSubscription(subscription Subscription, firstTransaction transaction.Transaction) SubscriptionFormatter {
+	formatter := FormatSubscription(subscription)
+
+	transactionFormatter := transaction.FormatTransaction(firstTransaction)
+	formatter.Transaction = &transactionFormatter
+
+	return formatter
+}
+
+func FormatSubscriptions(subscriptions []Subscription) []SubscriptionFormatter {
+	subscriptionsFormatter := []SubscriptionFormatter{}
+
+	for _, subscription := range subscriptions {
+		formatter := FormatSubscription(subscription)
+		subscriptionsFormatter = append(subscriptionsFormatter, formatter)
+	}
+
+	return subscriptionsFormatter
+}
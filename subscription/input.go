@@ -0,0 +1,14 @@
+package subscription
+
+import "cfa-backend/user"
+
+type CreateSubscriptionInput struct {
+	CampaignID int `json:"campaign_id" binding:"required"`
+	Amount     int `json:"amount" binding:"required"`
+	User       user.User
+}
+
+type GetSubscriptionDetailInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
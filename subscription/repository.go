@@ -0,0 +1,147 @@
+package subscription
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindByID(ID int) (Subscription, error)
+	FindByUserID(userID int) ([]Subscription, error)
+	FindDue(now time.Time, limit int) ([]Subscription, error)
+	Save(subscription Subscription) (Subscription, error)
+	UpdateStatus(subscription Subscription, fromStatus string) (bool, error)
+	Resume(subscription Subscription) (bool, error)
+	ClaimCycle(subscription Subscription, nextBillingAt time.Time) (bool, error)
+	ReleaseCycle(subscription Subscription, nextBillingAt time.Time, nextAttempt time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindByID(ID int) (Subscription, error) {
+	var subscription Subscription
+	err := r.db.Preload("Campaign").Where("id = ?", ID).Find(&subscription).Error
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+func (r *repository) FindByUserID(userID int) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := r.db.Preload("Campaign").Where("user_id = ?", userID).Order("id DESC").Find(&subscriptions).Error
+
+	if err != nil {
+		return subscriptions, err
+	}
+
+	return subscriptions, nil
+}
+
+// FindDue returns active subscriptions whose cycle is due, leaving out those
+// whose next try after a failed one is still after now.
+func (r *repository) FindDue(now time.Time, limit int) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := r.db.Preload("User").Preload("Campaign").Where("status = ? AND next_billing_at <= ?", StatusActive, now).
+		Where("next_billing_attempt_at IS NULL OR next_billing_attempt_at <= ?", now).
+		Order("next_billing_at ASC").Limit(limit).Find(&subscriptions).Error
+
+	if err != nil {
+		return subscriptions, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *repository) Save(subscription Subscription) (Subscription, error) {
+	err := r.db.Omit(clause.Associations).Create(&subscription).Error
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateStatus stores the status and cancellation time of a subscription,
+// but only if it is still in fromStatus, so a stale copy never overwrites a
+// change made in the meantime. It reports whether the subscription was
+// updated.
+func (r *repository) UpdateStatus(subscription Subscription, fromStatus string) (bool, error) {
+	result := r.db.Model(&Subscription{}).
+		Where("id = ? AND status = ?", subscription.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":       subscription.Status,
+			"cancelled_at": subscription.CancelledAt,
+		})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Resume activates a paused subscription again from its new next billing
+// date. It reports whether the subscription was still paused.
+func (r *repository) Resume(subscription Subscription) (bool, error) {
+	result := r.db.Model(&Subscription{}).
+		Where("id = ? AND status = ?", subscription.ID, StatusPaused).
+		Updates(map[string]interface{}{
+			"status":                  StatusActive,
+			"next_billing_at":         subscription.NextBillingAt,
+			"billing_attempts":        0,
+			"next_billing_attempt_at": nil,
+		})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ClaimCycle moves an active subscription's next billing date forward, but
+// only if nobody else has billed the cycle yet, and starts the count of failed
+// tries over for the next cycle. It reports whether the cycle was claimed.
+func (r *repository) ClaimCycle(subscription Subscription, nextBillingAt time.Time) (bool, error) {
+	result := r.db.Model(&Subscription{}).
+		Where("id = ? AND status = ? AND next_billing_at = ?", subscription.ID, StatusActive, subscription.NextBillingAt).
+		Updates(map[string]interface{}{
+			"next_billing_at":         nextBillingAt,
+			"last_billed_at":          time.Now(),
+			"billing_attempts":        0,
+			"next_billing_attempt_at": nil,
+		})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseCycle hands a cycle claimed with ClaimCycle back, moving the next
+// billing date back to what it was, counts the failed try and puts off the
+// next one until nextAttempt. Nothing changes when the subscription has moved
+// on since.
+func (r *repository) ReleaseCycle(subscription Subscription, nextBillingAt time.Time, nextAttempt time.Time) error {
+	return r.db.Model(&Subscription{}).
+		Where("id = ? AND status = ? AND next_billing_at = ?", subscription.ID, StatusActive, nextBillingAt).
+		Updates(map[string]interface{}{
+			"next_billing_at":         subscription.NextBillingAt,
+			"last_billed_at":          subscription.LastBilledAt,
+			"billing_attempts":        subscription.BillingAttempts + 1,
+			"next_billing_attempt_at": nextAttempt,
+		}).Error
+}
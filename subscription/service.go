@@ -0,0 +1,342 @@
+package subscription
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/helper"
+	"cfa-backend/mailer"
+	"cfa-backend/transaction"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// billingBatchSize is how many due subscriptions are billed per worker run.
+const billingBatchSize = 100
+
+// maxBillingAttempts is how many times a cycle is tried before it is skipped.
+// With the backoff below that is about 20 hours.
+const maxBillingAttempts = 8
+
+// billingRetryDelay is how long a cycle that failed to bill is left alone
+// before the next try. The delay doubles with every failed try up to
+// maxBillingRetryDelay.
+const (
+	billingRetryDelay    = 15 * time.Minute
+	maxBillingRetryDelay = 6 * time.Hour
+)
+
+var ErrSubscriptionChanged = errors.New("Subscription was changed in the meantime, please try again!")
+
+type Service interface {
+	CreateSubscription(input CreateSubscriptionInput) (Subscription, transaction.Transaction, error)
+	GetUserSubscriptions(userID int) ([]Subscription, error)
+	PauseSubscription(input GetSubscriptionDetailInput) (Subscription, error)
+	ResumeSubscription(input GetSubscriptionDetailInput) (Subscription, error)
+	CancelSubscription(input GetSubscriptionDetailInput) (Subscription, error)
+	BillDueSubscriptions(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repository         Repository
+	campaignRepository campaign.Repository
+	transactionService transaction.Service
+	mailer             mailer.Mailer
+	paymentWindow      time.Duration
+}
+
+// NewService bills subscriptions through the transaction service. Backers
+// are emailed the payment link of every cycle, which they have paymentWindow
+// to pay before the transaction expires.
+func NewService(repository Repository, campaignRepository campaign.Repository, transactionService transaction.Service, mailer mailer.Mailer, paymentWindow time.Duration) *service {
+	return &service{
+		repository:         repository,
+		campaignRepository: campaignRepository,
+		transactionService: transactionService,
+		mailer:             mailer,
+		paymentWindow:      paymentWindow,
+	}
+}
+
+// CreateSubscription starts a monthly pledge and bills its first cycle right
+// away, returning the transaction of that first cycle so the backer can pay.
+func (s *service) CreateSubscription(input CreateSubscriptionInput) (Subscription, transaction.Transaction, error) {
+	campaign, err := s.campaignRepository.FindByID(input.CampaignID)
+	if err != nil {
+		return Subscription{}, transaction.Transaction{}, err
+	}
+
+	if campaign.ID == 0 {
+		return Subscription{}, transaction.Transaction{}, errors.New("No campaign found with that ID")
+	}
+
+	if input.Amount < transaction.MinimumAmount {
+		return Subscription{}, transaction.Transaction{}, errors.New("Donation amount is below the minimum amount!")
+	}
+
+	now := time.Now()
+	subscription := Subscription{
+		UserID:        input.User.ID,
+		CampaignID:    campaign.ID,
+		Amount:        input.Amount,
+		Status:        StatusActive,
+		BillingDay:    now.Day(),
+		NextBillingAt: nextBillingDate(now, now.Day()),
+		LastBilledAt:  &now,
+	}
+
+	newSubscription, err := s.repository.Save(subscription)
+	if err != nil {
+		return newSubscription, transaction.Transaction{}, err
+	}
+
+	firstTransaction, err := s.transactionService.CreateTransaction(transaction.CreateTransactionInput{
+		Amount:         newSubscription.Amount,
+		CampaignID:     newSubscription.CampaignID,
+		SubscriptionID: newSubscription.ID,
+		User:           input.User,
+	})
+	if err != nil {
+		newSubscription.Status = StatusCancelled
+		newSubscription.CancelledAt = &now
+		s.repository.UpdateStatus(newSubscription, StatusActive)
+
+		return newSubscription, firstTransaction, err
+	}
+
+	newSubscription.Campaign = campaign
+
+	return newSubscription, firstTransaction, nil
+}
+
+func (s *service) GetUserSubscriptions(userID int) ([]Subscription, error) {
+	subscriptions, err := s.repository.FindByUserID(userID)
+	if err != nil {
+		return subscriptions, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *service) PauseSubscription(input GetSubscriptionDetailInput) (Subscription, error) {
+	subscription, err := s.findOwnSubscription(input)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.Status != StatusActive {
+		return subscription, errors.New("Only active subscriptions can be paused!")
+	}
+
+	subscription.Status = StatusPaused
+
+	updated, err := s.repository.UpdateStatus(subscription, StatusActive)
+	if err != nil {
+		return subscription, err
+	}
+
+	if !updated {
+		return subscription, ErrSubscriptionChanged
+	}
+
+	return subscription, nil
+}
+
+// ResumeSubscription reactivates a paused subscription. Cycles that fell in
+// the pause are not billed afterwards.
+func (s *service) ResumeSubscription(input GetSubscriptionDetailInput) (Subscription, error) {
+	subscription, err := s.findOwnSubscription(input)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.Status != StatusPaused {
+		return subscription, errors.New("Only paused subscriptions can be resumed!")
+	}
+
+	now := time.Now()
+	for subscription.NextBillingAt.Before(now) {
+		subscription.NextBillingAt = nextBillingDate(subscription.NextBillingAt, subscription.BillingDay)
+	}
+	subscription.Status = StatusActive
+
+	updated, err := s.repository.Resume(subscription)
+	if err != nil {
+		return subscription, err
+	}
+
+	if !updated {
+		return subscription, ErrSubscriptionChanged
+	}
+
+	return subscription, nil
+}
+
+func (s *service) CancelSubscription(input GetSubscriptionDetailInput) (Subscription, error) {
+	subscription, err := s.findOwnSubscription(input)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.Status == StatusCancelled {
+		return subscription, errors.New("Subscription has already been cancelled!")
+	}
+
+	fromStatus := subscription.Status
+
+	now := time.Now()
+	subscription.Status = StatusCancelled
+	subscription.CancelledAt = &now
+
+	updated, err := s.repository.UpdateStatus(subscription, fromStatus)
+	if err != nil {
+		return subscription, err
+	}
+
+	if !updated {
+		return subscription, ErrSubscriptionChanged
+	}
+
+	return subscription, nil
+}
+
+// BillDueSubscriptions creates the transaction of every active subscription
+// whose billing date has come and emails the backer its payment link. Each
+// cycle is claimed before it is billed, so it is never billed twice, and
+// released again when billing fails for a reason that may pass, e.g. the
+// gateway being down, so that a later run retries it with a growing delay; a
+// cycle that still fails after maxBillingAttempts tries is skipped. A
+// subscription that can never be billed again, because its campaign is gone,
+// no longer accepts donations or no longer accepts its amount, is cancelled.
+// It returns how many subscriptions were billed.
+func (s *service) BillDueSubscriptions(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	subscriptions, err := s.repository.FindDue(now, billingBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	billed := 0
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return billed, ctx.Err()
+		}
+
+		nextBillingAt := nextBillingDate(subscription.NextBillingAt, subscription.BillingDay)
+		for !nextBillingAt.After(now) {
+			nextBillingAt = nextBillingDate(nextBillingAt, subscription.BillingDay)
+		}
+
+		claimed, err := s.repository.ClaimCycle(subscription, nextBillingAt)
+		if err != nil || !claimed {
+			continue
+		}
+
+		newTransaction, err := s.transactionService.CreateTransaction(transaction.CreateTransactionInput{
+			Amount:         subscription.Amount,
+			CampaignID:     subscription.CampaignID,
+			SubscriptionID: subscription.ID,
+			User:           subscription.User,
+		})
+		if isPermanent(err) {
+			log.Printf("cancelling subscription %d: %v", subscription.ID, err)
+
+			subscription.Status = StatusCancelled
+			subscription.CancelledAt = &now
+			_, err = s.repository.UpdateStatus(subscription, StatusActive)
+			if err != nil {
+				return billed, err
+			}
+			continue
+		}
+
+		if err != nil {
+			log.Printf("failed to bill subscription %d: %v", subscription.ID, err)
+
+			// The cycle stays claimed once it has been tried often enough,
+			// so the subscription goes on with the next one.
+			if subscription.BillingAttempts+1 >= maxBillingAttempts {
+				log.Printf("skipping the cycle of subscription %d after %d tries", subscription.ID, maxBillingAttempts)
+				continue
+			}
+
+			err = s.repository.ReleaseCycle(subscription, nextBillingAt, now.Add(billingBackoff(subscription.BillingAttempts)))
+			if err != nil {
+				return billed, err
+			}
+			continue
+		}
+
+		billed++
+
+		// The cycle is billed either way; without the email the backer can
+		// still pay from their transaction list.
+		err = s.sendPaymentLink(subscription, newTransaction)
+		if err != nil {
+			log.Printf("failed to email the payment link of subscription %d: %v", subscription.ID, err)
+		}
+	}
+
+	return billed, nil
+}
+
+// billingBackoff returns how long to wait before billing a cycle again after
+// attempts failed tries.
+func billingBackoff(attempts int) time.Duration {
+	delay := billingRetryDelay
+	for i := 0; i < attempts && delay < maxBillingRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBillingRetryDelay)
+}
+
+// sendPaymentLink emails the backer of a subscription the payment page of the
+// transaction billed for its cycle.
+func (s *service) sendPaymentLink(subscription Subscription, newTransaction transaction.Transaction) error {
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Your monthly donation of %s to %s is due.\n\n"+
+		"Complete your payment here:\n%s\n\n"+
+		"The link expires if the payment is not completed within %d hours.\n",
+		subscription.User.Name, helper.FormatRupiah(subscription.Amount), subscription.Campaign.Name, newTransaction.PaymentURL, int(s.paymentWindow.Hours()))
+
+	return s.mailer.Send(subscription.User.Email, "Your monthly donation "+newTransaction.Code, body)
+}
+
+// isPermanent reports whether billing failed for a reason that retrying does
+// not fix.
+func isPermanent(err error) bool {
+	return errors.Is(err, transaction.ErrNotAcceptingDonations) ||
+		errors.Is(err, transaction.ErrCampaignNotFound) ||
+		errors.Is(err, transaction.ErrBelowMinimumAmount)
+}
+
+func (s *service) findOwnSubscription(input GetSubscriptionDetailInput) (Subscription, error) {
+	subscription, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.ID == 0 || subscription.UserID != input.User.ID {
+		return subscription, errors.New("No subscription found with that ID")
+	}
+
+	return subscription, nil
+}
+
+// nextBillingDate returns the billing date one month after from, on
+// billingDay or on the last day of the month for shorter months.
+func nextBillingDate(from time.Time, billingDay int) time.Time {
+	year, month, _ := from.Date()
+	firstOfNextMonth := time.Date(year, month+1, 1, from.Hour(), from.Minute(), from.Second(), 0, from.Location())
+	lastDay := firstOfNextMonth.AddDate(0, 1, -1).Day()
+
+	day := billingDay
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return firstOfNextMonth.AddDate(0, 0, day-1)
+}
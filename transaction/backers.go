@@ -0,0 +1,55 @@
+package transaction
+
+import (
+	"cfa-backend/campaign"
+	"cfa-backend/mailer"
+	"fmt"
+)
+
+// backerDirectory implements campaign.BackerDirectory on top of the
+// transactions: everyone with a paid donation to a campaign is a backer.
+type backerDirectory struct {
+	repository Repository
+	mailer     mailer.Mailer
+	appURL     string
+}
+
+func NewBackerDirectory(repository Repository, mailer mailer.Mailer, appURL string) *backerDirectory {
+	return &backerDirectory{repository: repository, mailer: mailer, appURL: appURL}
+}
+
+func (d *backerDirectory) IsBacker(campaignID int, userID int) (bool, error) {
+	return d.repository.IsBacker(campaignID, userID)
+}
+
+// GetBackerEmails returns the distinct email addresses of the backers of a
+// campaign, skipping backers without one.
+func (d *backerDirectory) GetBackerEmails(campaignID int) ([]string, error) {
+	emails, err := d.repository.GetBackerEmails(campaignID)
+	if err != nil {
+		return []string{}, err
+	}
+
+	reachable := []string{}
+	for _, email := range emails {
+		if email != "" {
+			reachable = append(reachable, email)
+		}
+	}
+
+	return reachable, nil
+}
+
+// NotifyBacker emails a new update to one backer of the campaign, so the
+// addresses of the backers are not shared.
+func (d *backerDirectory) NotifyBacker(email string, campaign campaign.Campaign, update campaign.CampaignUpdate) error {
+	updateURL := fmt.Sprintf("%s/api/v1/campaign/%d/updates/%d", d.appURL, campaign.ID, update.ID)
+	subject := fmt.Sprintf("%s: %s", campaign.Name, update.Title)
+	body := fmt.Sprintf("Hi,\n\n"+
+		"%s, a campaign you backed, posted an update.\n\n"+
+		"%s\n\n%s\n\n"+
+		"Read it online:\n%s\n",
+		campaign.Name, update.Title, update.Body, updateURL)
+
+	return d.mailer.Send(email, subject, body)
+}
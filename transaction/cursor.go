@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidCursor = errors.New("Invalid pagination cursor!")
+
+// encodeCursor returns the opaque cursor pointing at a transaction. The sort
+// order is part of the cursor so it cannot be reused with another order.
+func encodeCursor(sort string, transaction Transaction) string {
+	raw := fmt.Sprintf("%s:%d:%d", sort, transaction.Amount, transaction.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(sort string, encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	_, err = fmt.Sscanf(string(raw), sort+":%d:%d", &cursor.Amount, &cursor.ID)
+	if err != nil || cursor.ID <= 0 || fmt.Sprintf("%s:%d:%d", sort, cursor.Amount, cursor.ID) != string(raw) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -2,20 +2,155 @@ package transaction
 
 import (
 	"cfa-backend/campaign"
+	"cfa-backend/guest"
 	"cfa-backend/user"
 	"time"
 )
 
+const (
+	StatusPending  = "pending"
+	StatusPaid     = "paid"
+	StatusSettled  = "settled"
+	StatusFailed   = "failed"
+	StatusExpired  = "expired"
+	StatusRefunded = "refunded"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+	// RefundStatusNeedsReview refunds kept an unknown outcome through every
+	// try. They are no longer retried; an operator has to check with the
+	// gateway whether the money left.
+	RefundStatusNeedsReview = "needs_review"
+)
+
 type Transaction struct {
-	ID         int
+	ID              int
+	CampaignID      int
+	UserID          int
+	GuestID         int
+	SubscriptionID  int
+	RewardTierID    int
+	Amount          int
+	Status          string
+	Code            string
+	PaymentURL      string
+	PaymentToken    string
+	PaymentMethod   string
+	RefundedAmount  int
+	PlatformFee     int
+	ProcessingFee   int
+	NetAmount       int
+	IsAnonymous     bool
+	HideAmount      bool
+	Message         string
+	ShippingAddress string
+	ExpiryAttempts  int
+	NextExpiryAt    *time.Time
+	ReviewReason    string
+	FlaggedAt       *time.Time
+	User            user.User
+	Guest           guest.Guest
+	Campaign        campaign.Campaign
+	Refunds         []Refund
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	// CampaignImages []campaign.CampaignImage
+}
+
+const (
+	SortNewest     = "newest"
+	SortOldest     = "oldest"
+	SortAmountDesc = "amount_desc"
+	SortAmountAsc  = "amount_asc"
+)
+
+// TransactionFilter narrows down a list of transactions. Zero values mean no
+// restriction; To is exclusive.
+type TransactionFilter struct {
 	CampaignID int
 	UserID     int
-	Amount     int
 	Status     string
-	Code       string
-	User       user.User
-	Campaign   campaign.Campaign
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	// CampaignImages []campaign.CampaignImage
+	From       time.Time
+	To         time.Time
+	MinAmount  int
+	MaxAmount  int
+}
+
+// PageQuery selects one page of a sorted list. The page starts right after
+// the transaction the cursor points at, or at the top when it is nil. A zero
+// Limit returns everything.
+type PageQuery struct {
+	Sort   string
+	Cursor *Cursor
+	Limit  int
+}
+
+// Cursor is the position of the last transaction of a page in its sort order.
+type Cursor struct {
+	ID     int
+	Amount int
+}
+
+// Page describes the page that was returned for a PageQuery.
+type Page struct {
+	Limit      int
+	NextCursor string
+	HasMore    bool
+}
+
+// SettledTotals sums up the settled, not refunded donations of a campaign and
+// the fees charged on them.
+type SettledTotals struct {
+	Amount int
+	Fees   int
+}
+
+// CampaignTotals sums up the donations a campaign has been paid: what came
+// in, what was refunded, the fees on what was kept, and the net amount that
+// is left for the campaign.
+type CampaignTotals struct {
+	Gross     int
+	Refunded  int
+	Fees      int
+	NetRaised int
+}
+
+type TransactionStatusHistory struct {
+	ID            int
+	TransactionID int
+	FromStatus    string
+	ToStatus      string
+	Actor         string
+	Reason        string
+	Payload       string
+	CreatedAt     time.Time
+}
+
+// Refund is a refund of a transaction. It is stored as pending, with the key
+// sent to the gateway, before the gateway is asked for it. A refund whose
+// outcome was unknown stays pending and is tried again under the same key at
+// NextAttemptAt, so the gateway never pays it out twice.
+type Refund struct {
+	ID            int
+	TransactionID int
+	Amount        int
+	Reason        string
+	Status        string
+	RefundKey     string
+	RequestedBy   int
+	FailureReason string
+	Attempts      int
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Withdrawals reports the money a campaign has paid out or is about to, which
+// refunds cannot hand back anymore. It is implemented by the payout
+// repository, as the payout package depends on this one.
+type Withdrawals interface {
+	SumWithdrawnByCampaignID(campaignID int) (int, error)
 }
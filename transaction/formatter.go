@@ -2,18 +2,39 @@ package transaction
 
 import "time"
 
+// AnonymousName is shown instead of the backer's name for anonymous donations.
+const AnonymousName = "Anonymous"
+
 type CampaignTransactionFormatter struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Amount    int       `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	IsAnonymous     bool      `json:"is_anonymous"`
+	Message         string    `json:"message"`
+	RewardTierID    int       `json:"reward_tier_id"`
+	ShippingAddress string    `json:"shipping_address"`
+	Amount          int       `json:"amount"`
+	PlatformFee     int       `json:"platform_fee"`
+	ProcessingFee   int       `json:"processing_fee"`
+	NetAmount       int       `json:"net_amount"`
+	RefundedAmount  int       `json:"refunded_amount"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 func FormatCampaignTransaction(transaction Transaction) CampaignTransactionFormatter {
 	formatter := CampaignTransactionFormatter{}
 	formatter.ID = transaction.ID
-	formatter.Name = transaction.User.Name
+	formatter.Name = backerName(transaction)
+	formatter.IsAnonymous = transaction.IsAnonymous
+	formatter.Message = transaction.Message
+	formatter.RewardTierID = transaction.RewardTierID
+	formatter.ShippingAddress = transaction.ShippingAddress
 	formatter.Amount = transaction.Amount
+	formatter.PlatformFee = transaction.PlatformFee
+	formatter.ProcessingFee = transaction.ProcessingFee
+	formatter.NetAmount = transaction.NetAmount
+	formatter.RefundedAmount = transaction.RefundedAmount
+	formatter.Status = transaction.Status
 	formatter.CreatedAt = transaction.CreatedAt
 
 	return formatter
@@ -34,12 +55,35 @@ func FormatCampaignTransactions(transactions []Transaction) []CampaignTransactio
 	return transactionsFormatter
 }
 
+type CampaignTotalsFormatter struct {
+	Gross     int `json:"gross"`
+	Refunded  int `json:"refunded"`
+	Fees      int `json:"fees"`
+	NetRaised int `json:"net_raised"`
+}
+
+func FormatCampaignTotals(totals CampaignTotals) CampaignTotalsFormatter {
+	formatter := CampaignTotalsFormatter{}
+	formatter.Gross = totals.Gross
+	formatter.Refunded = totals.Refunded
+	formatter.Fees = totals.Fees
+	formatter.NetRaised = totals.NetRaised
+
+	return formatter
+}
+
 type UserTransactionFormatter struct {
-	ID        int                              `json:"id"`
-	Amount    int                              `json:"amount"`
-	Status    string                           `json:"status"`
-	CreatedAt time.Time                        `json:"created_at"`
-	Campaign  UserCampaignTransactionFormatter `json:"campaign"`
+	ID             int                              `json:"id"`
+	Code           string                           `json:"code"`
+	Amount         int                              `json:"amount"`
+	Status         string                           `json:"status"`
+	RefundedAmount int                              `json:"refunded_amount"`
+	RefundStatus   string                           `json:"refund_status"`
+	PaymentURL     string                           `json:"payment_url"`
+	SubscriptionID int                              `json:"subscription_id"`
+	RewardTierID   int                              `json:"reward_tier_id"`
+	CreatedAt      time.Time                        `json:"created_at"`
+	Campaign       UserCampaignTransactionFormatter `json:"campaign"`
 }
 
 type UserCampaignTransactionFormatter struct {
@@ -50,8 +94,14 @@ type UserCampaignTransactionFormatter struct {
 func FormatUserTransaction(transaction Transaction) UserTransactionFormatter {
 	formatter := UserTransactionFormatter{}
 	formatter.ID = transaction.ID
+	formatter.Code = transaction.Code
 	formatter.Amount = transaction.Amount
 	formatter.Status = transaction.Status
+	formatter.RefundedAmount = transaction.RefundedAmount
+	formatter.RefundStatus = refundStatus(transaction)
+	formatter.PaymentURL = transaction.PaymentURL
+	formatter.SubscriptionID = transaction.SubscriptionID
+	formatter.RewardTierID = transaction.RewardTierID
 	formatter.CreatedAt = transaction.CreatedAt
 
 	userCampaignTransactionFormatter := UserCampaignTransactionFormatter{}
@@ -81,3 +131,236 @@ func FormatUserTransactions(transactions []Transaction) []UserTransactionFormatt
 
 	return transactionsFormatter
 }
+
+type TransactionFormatter struct {
+	ID           int       `json:"id"`
+	CampaignID   int       `json:"campaign_id"`
+	UserID       int       `json:"user_id"`
+	Amount       int       `json:"amount"`
+	Status       string    `json:"status"`
+	Code         string    `json:"code"`
+	PaymentURL   string    `json:"payment_url"`
+	PaymentToken string    `json:"payment_token"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func FormatTransaction(transaction Transaction) TransactionFormatter {
+	formatter := TransactionFormatter{}
+	formatter.ID = transaction.ID
+	formatter.CampaignID = transaction.CampaignID
+	formatter.UserID = transaction.UserID
+	formatter.Amount = transaction.Amount
+	formatter.Status = transaction.Status
+	formatter.Code = transaction.Code
+	formatter.PaymentURL = transaction.PaymentURL
+	formatter.PaymentToken = transaction.PaymentToken
+	formatter.CreatedAt = transaction.CreatedAt
+
+	return formatter
+}
+
+type FlaggedTransactionFormatter struct {
+	ID            int        `json:"id"`
+	CampaignID    int        `json:"campaign_id"`
+	CampaignName  string     `json:"campaign_name"`
+	Code          string     `json:"code"`
+	Amount        int        `json:"amount"`
+	Status        string     `json:"status"`
+	PaymentMethod string     `json:"payment_method"`
+	ReviewReason  string     `json:"review_reason"`
+	FlaggedAt     *time.Time `json:"flagged_at"`
+}
+
+func FormatFlaggedTransaction(transaction Transaction) FlaggedTransactionFormatter {
+	formatter := FlaggedTransactionFormatter{}
+	formatter.ID = transaction.ID
+	formatter.CampaignID = transaction.CampaignID
+	formatter.CampaignName = transaction.Campaign.Name
+	formatter.Code = transaction.Code
+	formatter.Amount = transaction.Amount
+	formatter.Status = transaction.Status
+	formatter.PaymentMethod = transaction.PaymentMethod
+	formatter.ReviewReason = transaction.ReviewReason
+	formatter.FlaggedAt = transaction.FlaggedAt
+
+	return formatter
+}
+
+func FormatFlaggedTransactions(transactions []Transaction) []FlaggedTransactionFormatter {
+	transactionsFormatter := []FlaggedTransactionFormatter{}
+
+	for _, transaction := range transactions {
+		formatter := FormatFlaggedTransaction(transaction)
+		transactionsFormatter = append(transactionsFormatter, formatter)
+	}
+
+	return transactionsFormatter
+}
+
+type TransactionStatusHistoryFormatter struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func FormatTransactionStatusHistory(history TransactionStatusHistory) TransactionStatusHistoryFormatter {
+	formatter := TransactionStatusHistoryFormatter{}
+	formatter.FromStatus = history.FromStatus
+	formatter.ToStatus = history.ToStatus
+	formatter.Actor = history.Actor
+	formatter.Reason = history.Reason
+	formatter.CreatedAt = history.CreatedAt
+
+	return formatter
+}
+
+func FormatTransactionStatusHistories(histories []TransactionStatusHistory) []TransactionStatusHistoryFormatter {
+	historiesFormatter := []TransactionStatusHistoryFormatter{}
+
+	for _, history := range histories {
+		formatter := FormatTransactionStatusHistory(history)
+		historiesFormatter = append(historiesFormatter, formatter)
+	}
+
+	return historiesFormatter
+}
+
+// refundStatus summarises how much of a transaction has been refunded: none,
+// partial or full.
+func refundStatus(transaction Transaction) string {
+	if transaction.RefundedAmount == 0 {
+		return "none"
+	}
+
+	if transaction.RefundedAmount < transaction.Amount {
+		return "partial"
+	}
+
+	return "full"
+}
+
+type RefundFormatter struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id"`
+	Amount        int       `json:"amount"`
+	Reason        string    `json:"reason"`
+	Status        string    `json:"status"`
+	FailureReason string    `json:"failure_reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func FormatRefund(refund Refund) RefundFormatter {
+	formatter := RefundFormatter{}
+	formatter.ID = refund.ID
+	formatter.TransactionID = refund.TransactionID
+	formatter.Amount = refund.Amount
+	formatter.Reason = refund.Reason
+	formatter.Status = refund.Status
+	formatter.FailureReason = refund.FailureReason
+	formatter.CreatedAt = refund.CreatedAt
+
+	return formatter
+}
+
+func FormatRefunds(refunds []Refund) []RefundFormatter {
+	refundsFormatter := []RefundFormatter{}
+
+	for _, refund := range refunds {
+		formatter := FormatRefund(refund)
+		refundsFormatter = append(refundsFormatter, formatter)
+	}
+
+	return refundsFormatter
+}
+
+type SupporterFormatter struct {
+	Name      string    `json:"name"`
+	ImageURL  string    `json:"image_url"`
+	Amount    *int      `json:"amount"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FormatSupporter formats a donation for the public supporter wall. Anonymous
+// donations show no name or avatar, and the amount is null when the backer
+// asked to hide it.
+func FormatSupporter(transaction Transaction) SupporterFormatter {
+	formatter := SupporterFormatter{}
+	formatter.Name = backerName(transaction)
+	formatter.Message = transaction.Message
+	formatter.CreatedAt = transaction.CreatedAt
+
+	if !transaction.IsAnonymous {
+		formatter.ImageURL = transaction.User.AvatarFileName
+	}
+
+	if !transaction.HideAmount {
+		amount := transaction.Amount
+		formatter.Amount = &amount
+	}
+
+	return formatter
+}
+
+func FormatSupporters(transactions []Transaction) []SupporterFormatter {
+	supportersFormatter := []SupporterFormatter{}
+
+	for _, transaction := range transactions {
+		formatter := FormatSupporter(transaction)
+		supportersFormatter = append(supportersFormatter, formatter)
+	}
+
+	return supportersFormatter
+}
+
+func backerName(transaction Transaction) string {
+	if transaction.IsAnonymous {
+		return AnonymousName
+	}
+
+	if transaction.UserID == 0 {
+		return transaction.Guest.Name
+	}
+
+	return transaction.User.Name
+}
+
+// ExportHeader is the header row of a campaign transactions export.
+var ExportHeader = []interface{}{
+	"ID", "Code", "Date", "Backer", "Email", "Anonymous", "Message", "Amount",
+	"Platform Fee", "Processing Fee", "Net Amount", "Refunded Amount", "Status", "Payment Method",
+	"Reward Tier ID", "Shipping Address",
+}
+
+// FormatExportRow formats a transaction as a row of a campaign transactions
+// export, matching ExportHeader. The email of anonymous backers is left out.
+func FormatExportRow(transaction Transaction) []interface{} {
+	email := ""
+	if !transaction.IsAnonymous {
+		email = transaction.User.Email
+		if transaction.UserID == 0 {
+			email = transaction.Guest.Email
+		}
+	}
+
+	return []interface{}{
+		transaction.ID,
+		transaction.Code,
+		transaction.CreatedAt.Format("2006-01-02 15:04:05"),
+		backerName(transaction),
+		email,
+		transaction.IsAnonymous,
+		transaction.Message,
+		transaction.Amount,
+		transaction.PlatformFee,
+		transaction.ProcessingFee,
+		transaction.NetAmount,
+		transaction.RefundedAmount,
+		transaction.Status,
+		transaction.PaymentMethod,
+		transaction.RewardTierID,
+		transaction.ShippingAddress,
+	}
+}
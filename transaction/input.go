@@ -1,8 +1,80 @@
 package transaction
 
-import "cfa-backend/user"
+import (
+	"cfa-backend/guest"
+	"cfa-backend/user"
+)
+
+type GetCampaignSupportersInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
 
 type GetCampaignIDTransactionInput struct {
 	ID   int `uri:"id" binding:"required"`
 	User user.User
 }
+
+type CreateTransactionInput struct {
+	Amount          int    `json:"amount" binding:"required"`
+	CampaignID      int    `json:"campaign_id" binding:"required"`
+	SubscriptionID  int    `json:"-"`
+	IsAnonymous     bool   `json:"is_anonymous"`
+	HideAmount      bool   `json:"hide_amount"`
+	Message         string `json:"message" binding:"max=280"`
+	RewardTierID    int    `json:"reward_tier_id"`
+	ShippingAddress string `json:"shipping_address" binding:"max=500"`
+	User            user.User
+	Guest           guest.Guest `json:"-"`
+}
+
+type CreateGuestTransactionInput struct {
+	Name            string `json:"name" binding:"required,max=100"`
+	Email           string `json:"email" binding:"required,email"`
+	Amount          int    `json:"amount" binding:"required"`
+	CampaignID      int    `json:"campaign_id" binding:"required"`
+	IsAnonymous     bool   `json:"is_anonymous"`
+	HideAmount      bool   `json:"hide_amount"`
+	Message         string `json:"message" binding:"max=280"`
+	RewardTierID    int    `json:"reward_tier_id"`
+	ShippingAddress string `json:"shipping_address" binding:"max=500"`
+}
+
+type GetGuestTransactionInput struct {
+	Code  string `uri:"code" binding:"required"`
+	Token string `form:"token" binding:"required"`
+}
+
+type ConfirmGuestMergeInput struct {
+	Token string `form:"token" binding:"required"`
+}
+
+type GetTransactionDetailInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type CreateRefundInput struct {
+	Amount int    `json:"amount"`
+	Reason string `json:"reason" binding:"required"`
+	User   user.User
+}
+
+type ListTransactionsInput struct {
+	Status    string `form:"status" binding:"omitempty,oneof=pending paid settled failed expired refunded"`
+	From      string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To        string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	MinAmount int    `form:"min_amount" binding:"omitempty,min=0"`
+	MaxAmount int    `form:"max_amount" binding:"omitempty,min=0"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=newest oldest amount_desc amount_asc"`
+	Cursor    string `form:"cursor"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ExportCampaignTransactionsInput struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv xlsx"`
+	From   string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To     string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Status string `form:"status" binding:"omitempty,oneof=pending paid settled failed expired refunded"`
+	User   user.User
+}
@@ -1,25 +1,67 @@
 package transaction
 
 import (
+	"cfa-backend/campaign"
+	"cfa-backend/ledger"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrDuplicateCode = errors.New("Transaction code is already in use!")
+
 type Repository interface {
-	GetTransactionByCampaignID(ID int) ([]Transaction, error)
-	GetTransactionByUserID(userID int) ([]Transaction, error)
+	GetTransactionByCampaignID(filter TransactionFilter, page PageQuery) ([]Transaction, error)
+	GetTransactionByUserID(filter TransactionFilter, page PageQuery) ([]Transaction, error)
+	FindByCode(code string) (Transaction, error)
+	Save(transaction Transaction, history TransactionStatusHistory) (Transaction, error)
+	Update(transaction Transaction) (Transaction, error)
+	FindByID(ID int) (Transaction, error)
+	Transition(transaction Transaction, history TransactionStatusHistory) (bool, error)
+	PostToLedger(transaction Transaction) error
+	GetStatusHistories(transactionID int) ([]TransactionStatusHistory, error)
+	FindPendingCreatedBefore(before time.Time, now time.Time, limit int) ([]Transaction, error)
+	DeferExpiry(transaction Transaction, nextTry time.Time) error
+	FlagForReview(transaction Transaction, history TransactionStatusHistory) (bool, error)
+	FindFlaggedForReview() ([]Transaction, error)
+	SaveRefundWithinBalance(refund Refund, withdrawals Withdrawals) (Refund, error)
+	ApplyRefund(refund Refund, history TransactionStatusHistory) (Refund, bool, error)
+	DeferRefund(refund Refund, nextAttempt time.Time) error
+	EscalateRefund(refund Refund) error
+	FailRefund(refund Refund) error
+	FindRefundsDue(now time.Time, limit int) ([]Refund, error)
+	GetRefunds(transactionID int) ([]Refund, error)
+	SumSettledByCampaignID(campaignID int) (SettledTotals, error)
+	SumTotalsByCampaignID(campaignID int) (CampaignTotals, error)
+	GetSupportersByCampaignID(campaignID int, limit int) ([]Transaction, error)
+	AssignGuestTransactions(guestID int, userID int) (int, error)
+	EachByFilter(filter TransactionFilter, batchSize int, fn func(Transaction) error) error
+	IsBacker(campaignID int, userID int) (bool, error)
+	GetBackerEmails(campaignID int) ([]string, error)
 }
 
 type repository struct {
 	db *gorm.DB
 }
 
+// openRefundStatuses are the refund statuses whose money may still leave.
+var openRefundStatuses = []string{RefundStatusPending, RefundStatusNeedsReview}
+
 func NewRepository(db *gorm.DB) *repository {
 	return &repository{db}
 }
 
-func (r *repository) GetTransactionByCampaignID(ID int) ([]Transaction, error) {
+// GetTransactionByCampaignID lists the transactions of filter.CampaignID. When
+// the page has a limit, one transaction more than the limit is returned so the
+// caller can tell whether another page follows.
+func (r *repository) GetTransactionByCampaignID(filter TransactionFilter, page PageQuery) ([]Transaction, error) {
 	var transaction []Transaction
-	err := r.db.Preload("User").Where("campaign_id = ?", ID).Order("id DESC").Find(&transaction).Error
+	query := applyFilter(r.db.Preload("User").Preload("Guest"), filter)
+	err := applyPage(query, page).Find(&transaction).Error
 
 	if err != nil {
 		return transaction, err
@@ -28,9 +70,69 @@ func (r *repository) GetTransactionByCampaignID(ID int) ([]Transaction, error) {
 	return transaction, nil
 }
 
-func (r *repository) GetTransactionByUserID(userID int) ([]Transaction, error) {
+// GetTransactionByUserID lists the transactions of filter.UserID, paginated
+// like GetTransactionByCampaignID.
+func (r *repository) GetTransactionByUserID(filter TransactionFilter, page PageQuery) ([]Transaction, error) {
 	var transaction []Transaction
-	err := r.db.Preload("Campaign.CampaignImages", "campaign_images.is_primary = 1").Where("user_id = ?", userID).Order("id DESC").Find(&transaction).Error
+	query := applyFilter(r.db.Preload("Campaign.CampaignImages", "campaign_images.is_primary = 1"), filter)
+	err := applyPage(query, page).Find(&transaction).Error
+
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (r *repository) FindByCode(code string) (Transaction, error) {
+	var transaction Transaction
+	err := r.db.Where("code = ?", code).Find(&transaction).Error
+
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// Save stores a new transaction together with the history row of its initial
+// status. It returns ErrDuplicateCode when another transaction already has
+// its code.
+func (r *repository) Save(transaction Transaction, history TransactionStatusHistory) (Transaction, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&transaction).Error
+		if err != nil {
+			return err
+		}
+
+		history.TransactionID = transaction.ID
+
+		return tx.Create(&history).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return transaction, ErrDuplicateCode
+	}
+
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (r *repository) Update(transaction Transaction) (Transaction, error) {
+	err := r.db.Save(&transaction).Error
+
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (r *repository) FindByID(ID int) (Transaction, error) {
+	var transaction Transaction
+	err := r.db.Preload("Campaign").Where("id = ?", ID).Find(&transaction).Error
 
 	if err != nil {
 		return transaction, err
@@ -38,3 +140,664 @@ func (r *repository) GetTransactionByUserID(userID int) ([]Transaction, error) {
 
 	return transaction, nil
 }
+
+// Transition moves a transaction from history.FromStatus to history.ToStatus
+// and records the history row. Campaign totals and the ledger follow the
+// money inside the same database transaction: a pending transaction that
+// becomes paid is added to them, and whatever was not refunded yet is taken
+// off again when a transaction becomes refunded. It reports false without changing anything
+// when the stored status is no longer history.FromStatus, e.g. because a
+// duplicate notification got there first.
+func (r *repository) Transition(transaction Transaction, history TransactionStatusHistory) (bool, error) {
+	updated := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transaction.ID).Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		if current.ID == 0 || current.Status != history.FromStatus {
+			return nil
+		}
+		updated = true
+
+		changes := map[string]interface{}{
+			"status":         history.ToStatus,
+			"payment_method": transaction.PaymentMethod,
+			"platform_fee":   transaction.PlatformFee,
+			"processing_fee": transaction.ProcessingFee,
+			"net_amount":     transaction.NetAmount,
+		}
+
+		if history.ToStatus == StatusRefunded {
+			changes["refunded_amount"] = current.Amount
+
+			err = refundFees(tx, current, current.Amount-current.RefundedAmount, "transaction-"+strconv.Itoa(current.ID), changes)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&Transaction{}).Where("id = ?", current.ID).Updates(changes).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(&history).Error
+		if err != nil {
+			return err
+		}
+
+		if history.FromStatus == StatusPending && history.ToStatus == StatusPaid {
+			err = updateCampaignTotals(tx, current.CampaignID, current.Amount, 1)
+			if err != nil {
+				return err
+			}
+		}
+
+		if history.ToStatus == StatusRefunded {
+			err = updateCampaignTotals(tx, current.CampaignID, -(current.Amount - current.RefundedAmount), -1)
+			if err != nil {
+				return err
+			}
+		}
+
+		return postLedger(tx, current.ID)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return updated, nil
+}
+
+// PostToLedger posts the journals implied by the status of a transaction that
+// are still missing, e.g. for a status applied before postings were made in
+// the same database transaction.
+func (r *repository) PostToLedger(transaction Transaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return postLedger(tx, transaction.ID)
+	})
+}
+
+// postLedger posts the journals implied by the stored status of a
+// transaction: its donation and fees once paid, and once refunded, whatever
+// its own refunds do not account for, which was refunded at the gateway
+// directly. Journals are posted once per reference, so posting again only
+// adds what is missing.
+func postLedger(tx *gorm.DB, transactionID int) error {
+	var current Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).Find(&current).Error
+	if err != nil {
+		return err
+	}
+
+	if current.Status != StatusPaid && current.Status != StatusSettled && current.Status != StatusRefunded {
+		return nil
+	}
+
+	err = ledger.PostJournal(tx, ledger.NewDonationJournal(current.ID, current.CampaignID, current.UserID, current.Amount))
+	if err != nil {
+		return err
+	}
+
+	fees := current.PlatformFee + current.ProcessingFee
+	if fees > 0 {
+		err = ledger.PostJournal(tx, ledger.NewFeeJournal(current.ID, current.CampaignID, fees))
+		if err != nil {
+			return err
+		}
+	}
+
+	if current.Status != StatusRefunded {
+		return nil
+	}
+
+	var refunded int
+	err = tx.Model(&Refund{}).Select("COALESCE(SUM(amount), 0)").Where("transaction_id = ? AND status = ?", current.ID, RefundStatusSucceeded).Scan(&refunded).Error
+	if err != nil {
+		return err
+	}
+
+	gatewayRefunded := current.Amount - refunded
+	if gatewayRefunded <= 0 {
+		return nil
+	}
+
+	return ledger.PostJournal(tx, ledger.NewRefundJournal("transaction-"+strconv.Itoa(current.ID), current.CampaignID, current.UserID, gatewayRefunded))
+}
+
+// SaveRefundWithinBalance stores a new pending refund, but only if it fits
+// both in what is left of the transaction after its other refunds and in the
+// funds the campaign has left after payouts and the refunds still open. The
+// transaction and campaign rows are locked while checking, the campaign lock
+// being the one payout requests take, so concurrent refunds and payouts
+// cannot hand out the same money twice.
+func (r *repository) SaveRefundWithinBalance(refund Refund, withdrawals Withdrawals) (Refund, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refund.TransactionID).Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		if current.Status != StatusPaid && current.Status != StatusSettled {
+			return ErrInvalidTransition
+		}
+
+		var locked campaign.Campaign
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", current.CampaignID).Find(&locked).Error
+		if err != nil {
+			return err
+		}
+
+		openOnTransaction, err := sumOpenRefunds(tx, "refunds.transaction_id = ?", current.ID)
+		if err != nil {
+			return err
+		}
+
+		if refund.Amount > current.Amount-current.RefundedAmount-openOnTransaction {
+			return ErrRefundExceedsAmount
+		}
+
+		var held int
+		err = tx.Model(&Transaction{}).Select("COALESCE(SUM(net_amount), 0)").Where("campaign_id = ? AND status IN ?", current.CampaignID, []string{StatusPaid, StatusSettled}).Scan(&held).Error
+		if err != nil {
+			return err
+		}
+
+		openOnCampaign, err := sumOpenRefunds(tx, "transactions.campaign_id = ?", current.CampaignID)
+		if err != nil {
+			return err
+		}
+
+		withdrawn, err := withdrawals.SumWithdrawnByCampaignID(current.CampaignID)
+		if err != nil {
+			return err
+		}
+
+		// The fees given back with the refund come out of the platform's
+		// pocket, not the campaign's.
+		platformFee, processingFee := prorateFees(current, refund.Amount)
+		cost := refund.Amount - (current.PlatformFee + current.ProcessingFee - platformFee - processingFee)
+
+		if cost > held-openOnCampaign-withdrawn {
+			return ErrRefundExceedsBalance
+		}
+
+		return tx.Create(&refund).Error
+	})
+
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+// ApplyRefund records a refund that the gateway has made. The refunded amount
+// is taken off the transaction, the campaign totals and the campaign's ledger
+// account, the fees charged on it are given back, and a refund that
+// brings the transaction to fully refunded also moves it to the refunded
+// status, recording history, and stops counting its backer; ApplyRefund then
+// reports true. A refund that is no longer open is left as it is. When a
+// gateway notification marked the transaction refunded first, its money has
+// been taken off already, so the refund is only marked succeeded.
+func (r *repository) ApplyRefund(refund Refund, history TransactionStatusHistory) (Refund, bool, error) {
+	completed := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refund.TransactionID).Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refund.ID).Find(&refund).Error
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(openRefundStatuses, refund.Status) {
+			return nil
+		}
+
+		if current.Status == StatusRefunded {
+			refund.Status = RefundStatusSucceeded
+			return tx.Model(&Refund{}).Where("id = ?", refund.ID).Update("status", refund.Status).Error
+		}
+
+		if current.Status != StatusPaid && current.Status != StatusSettled {
+			return ErrInvalidTransition
+		}
+
+		refundedAmount := current.RefundedAmount + refund.Amount
+		if refundedAmount > current.Amount {
+			return ErrRefundExceedsAmount
+		}
+
+		changes := map[string]interface{}{"refunded_amount": refundedAmount}
+		backerChange := 0
+
+		err = refundFees(tx, current, refund.Amount, strconv.Itoa(refund.ID), changes)
+		if err != nil {
+			return err
+		}
+
+		if refundedAmount == current.Amount {
+			changes["status"] = StatusRefunded
+			backerChange = -1
+			completed = true
+
+			history.TransactionID = current.ID
+			history.FromStatus = current.Status
+			history.ToStatus = StatusRefunded
+
+			err = tx.Create(&history).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&Transaction{}).Where("id = ?", current.ID).Updates(changes).Error
+		if err != nil {
+			return err
+		}
+
+		refund.Status = RefundStatusSucceeded
+		err = tx.Model(&Refund{}).Where("id = ?", refund.ID).Update("status", refund.Status).Error
+		if err != nil {
+			return err
+		}
+
+		err = updateCampaignTotals(tx, current.CampaignID, -refund.Amount, backerChange)
+		if err != nil {
+			return err
+		}
+
+		return ledger.PostJournal(tx, ledger.NewRefundJournal(strconv.Itoa(refund.ID), current.CampaignID, current.UserID, refund.Amount))
+	})
+
+	if err != nil {
+		return refund, false, err
+	}
+
+	return refund, completed, nil
+}
+
+// DeferRefund counts a try of a pending refund whose outcome is unknown and
+// puts off the next one until nextAttempt.
+func (r *repository) DeferRefund(refund Refund, nextAttempt time.Time) error {
+	return r.db.Model(&Refund{}).Where("id = ? AND status = ?", refund.ID, RefundStatusPending).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"failure_reason":  refund.FailureReason,
+		"next_attempt_at": nextAttempt,
+	}).Error
+}
+
+// EscalateRefund counts the last try of a pending refund whose outcome is
+// still unknown and sets it aside for an operator.
+func (r *repository) EscalateRefund(refund Refund) error {
+	return r.db.Model(&Refund{}).Where("id = ? AND status = ?", refund.ID, RefundStatusPending).Updates(map[string]interface{}{
+		"status":          RefundStatusNeedsReview,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"failure_reason":  refund.FailureReason,
+		"next_attempt_at": nil,
+	}).Error
+}
+
+// FailRefund marks a pending refund that the gateway refused as failed.
+func (r *repository) FailRefund(refund Refund) error {
+	return r.db.Model(&Refund{}).Where("id = ? AND status = ?", refund.ID, RefundStatusPending).Updates(map[string]interface{}{
+		"status":         RefundStatusFailed,
+		"failure_reason": refund.FailureReason,
+	}).Error
+}
+
+// FindRefundsDue returns pending refunds whose next try is due, oldest first.
+func (r *repository) FindRefundsDue(now time.Time, limit int) ([]Refund, error) {
+	var refunds []Refund
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", RefundStatusPending, now).Order("id ASC").Limit(limit).Find(&refunds).Error
+
+	if err != nil {
+		return refunds, err
+	}
+
+	return refunds, nil
+}
+
+func (r *repository) GetRefunds(transactionID int) ([]Refund, error) {
+	var refunds []Refund
+	err := r.db.Where("transaction_id = ?", transactionID).Order("id DESC").Find(&refunds).Error
+
+	if err != nil {
+		return refunds, err
+	}
+
+	return refunds, nil
+}
+
+// refundFees takes the fees of the refunded part of a transaction off it in
+// proportion to the amount refunded, adding the column changes to changes,
+// and gives them back to the campaign in the ledger. The donation and its
+// fees are posted first, so there is always a fee journal to give back from.
+func refundFees(tx *gorm.DB, current Transaction, amount int, reference string, changes map[string]interface{}) error {
+	platformFee, processingFee := prorateFees(current, amount)
+
+	changes["platform_fee"] = platformFee
+	changes["processing_fee"] = processingFee
+	changes["net_amount"] = current.Amount - current.RefundedAmount - amount - platformFee - processingFee
+
+	returned := current.PlatformFee + current.ProcessingFee - platformFee - processingFee
+	if returned <= 0 {
+		return nil
+	}
+
+	err := postLedger(tx, current.ID)
+	if err != nil {
+		return err
+	}
+
+	return ledger.PostJournal(tx, ledger.NewFeeRefundJournal(reference, current.CampaignID, returned))
+}
+
+// prorateFees returns the platform and processing fees left on a transaction
+// once amount more of it is refunded: the fees shrink with what is left, and
+// are gone once everything is refunded.
+func prorateFees(transaction Transaction, amount int) (int, int) {
+	remaining := transaction.Amount - transaction.RefundedAmount
+	left := remaining - amount
+	if remaining <= 0 || left <= 0 {
+		return 0, 0
+	}
+
+	return transaction.PlatformFee * left / remaining, transaction.ProcessingFee * left / remaining
+}
+
+// SumSettledByCampaignID returns how much of a campaign's donations has been
+// settled by the gateway and not refunded since, and the fees charged on them.
+// Refunds that are still open are taken off the amount already, as their
+// money may leave at any moment.
+func (r *repository) SumSettledByCampaignID(campaignID int) (SettledTotals, error) {
+	var settled SettledTotals
+	err := r.db.Model(&Transaction{}).
+		Select("COALESCE(SUM(amount - refunded_amount), 0) AS amount, COALESCE(SUM(platform_fee + processing_fee), 0) AS fees").
+		Where("campaign_id = ? AND status = ?", campaignID, StatusSettled).
+		Scan(&settled).Error
+
+	if err != nil {
+		return settled, err
+	}
+
+	open, err := sumOpenRefunds(r.db, "transactions.campaign_id = ?", campaignID)
+	if err != nil {
+		return settled, err
+	}
+	settled.Amount -= open
+
+	return settled, nil
+}
+
+// SumTotalsByCampaignID sums up every donation of a campaign that has been
+// paid, including the ones refunded since.
+func (r *repository) SumTotalsByCampaignID(campaignID int) (CampaignTotals, error) {
+	var totals CampaignTotals
+	err := r.db.Model(&Transaction{}).
+		Select("COALESCE(SUM(amount), 0) AS gross, COALESCE(SUM(refunded_amount), 0) AS refunded, COALESCE(SUM(platform_fee + processing_fee), 0) AS fees, COALESCE(SUM(net_amount), 0) AS net_raised").
+		Where("campaign_id = ? AND status IN ?", campaignID, []string{StatusPaid, StatusSettled, StatusRefunded}).
+		Scan(&totals).Error
+
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
+
+// sumOpenRefunds sums the refunds that are still open among those matching
+// the condition, which may refer to the refunds and transactions tables.
+func sumOpenRefunds(db *gorm.DB, condition string, args ...interface{}) (int, error) {
+	var open int
+	err := db.Model(&Refund{}).
+		Select("COALESCE(SUM(refunds.amount), 0)").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("refunds.status IN ?", openRefundStatuses).
+		Where(condition, args...).
+		Scan(&open).Error
+
+	if err != nil {
+		return open, err
+	}
+
+	return open, nil
+}
+
+func (r *repository) GetSupportersByCampaignID(campaignID int, limit int) ([]Transaction, error) {
+	var transactions []Transaction
+	err := r.db.Preload("User").Preload("Guest").Where("campaign_id = ? AND status IN ?", campaignID, []string{StatusPaid, StatusSettled}).Order("id DESC").Limit(limit).Find(&transactions).Error
+
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
+
+// IsBacker reports whether the user has a paid donation to the campaign.
+func (r *repository) IsBacker(campaignID int, userID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&Transaction{}).Where("campaign_id = ? AND user_id = ? AND status IN ?", campaignID, userID, []string{StatusPaid, StatusSettled}).Count(&count).Error
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetBackerEmails returns the distinct email addresses of everyone with a
+// paid donation to the campaign, registered users and guests alike.
+func (r *repository) GetBackerEmails(campaignID int) ([]string, error) {
+	var emails []string
+	err := r.db.Model(&Transaction{}).
+		Joins("LEFT JOIN users ON users.id = transactions.user_id").
+		Joins("LEFT JOIN guests ON guests.id = transactions.guest_id").
+		Where("transactions.campaign_id = ? AND transactions.status IN ?", campaignID, []string{StatusPaid, StatusSettled}).
+		Distinct().
+		Pluck("COALESCE(users.email, guests.email)", &emails).Error
+
+	if err != nil {
+		return emails, err
+	}
+
+	return emails, nil
+}
+
+// AssignGuestTransactions hands the transactions of a guest over to a user and
+// returns how many were moved.
+func (r *repository) AssignGuestTransactions(guestID int, userID int) (int, error) {
+	result := r.db.Model(&Transaction{}).Where("guest_id = ? AND user_id = 0", guestID).Update("user_id", userID)
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// EachByFilter calls fn for every transaction matching the filter, oldest
+// first. Transactions are loaded batchSize at a time so that only one batch is
+// held in memory; an error from fn stops the iteration and is returned.
+func (r *repository) EachByFilter(filter TransactionFilter, batchSize int, fn func(Transaction) error) error {
+	var batch []Transaction
+	query := applyFilter(r.db.Preload("User").Preload("Guest"), filter)
+
+	return query.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		for _, transaction := range batch {
+			err := fn(transaction)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}).Error
+}
+
+func applyFilter(db *gorm.DB, filter TransactionFilter) *gorm.DB {
+	if filter.CampaignID != 0 {
+		db = db.Where("campaign_id = ?", filter.CampaignID)
+	}
+
+	if filter.UserID != 0 {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+
+	if filter.MinAmount != 0 {
+		db = db.Where("amount >= ?", filter.MinAmount)
+	}
+
+	if filter.MaxAmount != 0 {
+		db = db.Where("amount <= ?", filter.MaxAmount)
+	}
+
+	if !filter.From.IsZero() {
+		db = db.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		db = db.Where("created_at < ?", filter.To)
+	}
+
+	return db
+}
+
+// applyPage orders the query by the page's sort and starts it after the
+// cursor. The id breaks ties between equal amounts, so every transaction has
+// exactly one position in each order.
+func applyPage(db *gorm.DB, page PageQuery) *gorm.DB {
+	cursor := page.Cursor
+
+	switch page.Sort {
+	case SortOldest:
+		db = db.Order("id ASC")
+		if cursor != nil {
+			db = db.Where("id > ?", cursor.ID)
+		}
+	case SortAmountDesc:
+		db = db.Order("amount DESC").Order("id DESC")
+		if cursor != nil {
+			db = db.Where("amount < ? OR (amount = ? AND id < ?)", cursor.Amount, cursor.Amount, cursor.ID)
+		}
+	case SortAmountAsc:
+		db = db.Order("amount ASC").Order("id ASC")
+		if cursor != nil {
+			db = db.Where("amount > ? OR (amount = ? AND id > ?)", cursor.Amount, cursor.Amount, cursor.ID)
+		}
+	default:
+		db = db.Order("id DESC")
+		if cursor != nil {
+			db = db.Where("id < ?", cursor.ID)
+		}
+	}
+
+	if page.Limit > 0 {
+		db = db.Limit(page.Limit + 1)
+	}
+
+	return db
+}
+
+func updateCampaignTotals(tx *gorm.DB, campaignID int, amount int, backers int) error {
+	return tx.Model(&campaign.Campaign{}).Where("id = ?", campaignID).Updates(map[string]interface{}{
+		"current_amount": gorm.Expr("current_amount + ?", amount),
+		"backer_count":   gorm.Expr("backer_count + ?", backers),
+	}).Error
+}
+
+func (r *repository) GetStatusHistories(transactionID int) ([]TransactionStatusHistory, error) {
+	var histories []TransactionStatusHistory
+	err := r.db.Where("transaction_id = ?", transactionID).Order("id ASC").Find(&histories).Error
+
+	if err != nil {
+		return histories, err
+	}
+
+	return histories, nil
+}
+
+// FindPendingCreatedBefore returns pending transactions created before the
+// given time, leaving out those whose next expiry try is still after now.
+func (r *repository) FindPendingCreatedBefore(before time.Time, now time.Time, limit int) ([]Transaction, error) {
+	var transactions []Transaction
+	err := r.db.Where("status = ? AND created_at < ?", StatusPending, before).
+		Where("next_expiry_at IS NULL OR next_expiry_at <= ?", now).
+		Order("id ASC").Limit(limit).Find(&transactions).Error
+
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
+
+// DeferExpiry counts a failed try to resolve a stale transaction and puts off
+// the next one until nextTry.
+func (r *repository) DeferExpiry(transaction Transaction, nextTry time.Time) error {
+	return r.db.Model(&Transaction{}).Where("id = ?", transaction.ID).Updates(map[string]interface{}{
+		"expiry_attempts": gorm.Expr("expiry_attempts + 1"),
+		"next_expiry_at":  nextTry,
+	}).Error
+}
+
+// FlagForReview sets a transaction aside for an operator with history.Reason
+// and records the history row, which keeps the transaction's status. It
+// reports false without changing anything when the transaction is flagged
+// already, e.g. because the gateway retried the notification.
+func (r *repository) FlagForReview(transaction Transaction, history TransactionStatusHistory) (bool, error) {
+	flagged := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Transaction{}).Where("id = ? AND flagged_at IS NULL", transaction.ID).Updates(map[string]interface{}{
+			"review_reason": history.Reason,
+			"flagged_at":    time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		flagged = true
+
+		history.TransactionID = transaction.ID
+		return tx.Create(&history).Error
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return flagged, nil
+}
+
+// FindFlaggedForReview returns the transactions flagged for an operator,
+// most recently flagged first.
+func (r *repository) FindFlaggedForReview() ([]Transaction, error) {
+	var transactions []Transaction
+	err := r.db.Preload("Campaign").Where("flagged_at IS NOT NULL").Order("flagged_at DESC").Find(&transactions).Error
+
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
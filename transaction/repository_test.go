@@ -0,0 +1,65 @@
+package transaction
+
+import "testing"
+
+func TestProrateFees(t *testing.T) {
+	tests := []struct {
+		name              string
+		transaction       Transaction
+		amount            int
+		wantPlatformFee   int
+		wantProcessingFee int
+	}{
+		{"nothing refunded", Transaction{Amount: 100000, PlatformFee: 5000, ProcessingFee: 4000}, 0, 5000, 4000},
+		{"partial refund", Transaction{Amount: 100000, PlatformFee: 5000, ProcessingFee: 4000}, 25000, 3750, 3000},
+		{"rounded down", Transaction{Amount: 100000, PlatformFee: 5000, ProcessingFee: 4000}, 33333, 3333, 2666},
+		{"after an earlier refund", Transaction{Amount: 100000, RefundedAmount: 30000, PlatformFee: 3500, ProcessingFee: 2800}, 20000, 2500, 2000},
+		{"rest refunded", Transaction{Amount: 100000, RefundedAmount: 30000, PlatformFee: 3500, ProcessingFee: 2800}, 70000, 0, 0},
+		{"more than the rest", Transaction{Amount: 100000, RefundedAmount: 30000, PlatformFee: 3500, ProcessingFee: 2800}, 80000, 0, 0},
+		{"already fully refunded", Transaction{Amount: 100000, RefundedAmount: 100000}, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			platformFee, processingFee := prorateFees(test.transaction, test.amount)
+			if platformFee != test.wantPlatformFee || processingFee != test.wantProcessingFee {
+				t.Errorf("prorateFees(%+v, %d) = %d, %d, want %d, %d", test.transaction, test.amount, platformFee, processingFee, test.wantPlatformFee, test.wantProcessingFee)
+			}
+		})
+	}
+}
+
+func TestProrateFeesAcrossRefunds(t *testing.T) {
+	tests := []struct {
+		name    string
+		refunds []int
+		want    [][2]int
+	}{
+		{"even refunds", []int{30000, 20000, 50000}, [][2]int{{3500, 2800}, {2500, 2000}, {0, 0}}},
+		{"uneven refunds", []int{33333, 33333, 33334}, [][2]int{{3333, 2666}, {1666, 1333}, {0, 0}}},
+		{"small refunds then the rest", []int{1, 1, 99998}, [][2]int{{4999, 3999}, {4998, 3998}, {0, 0}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Each refund is applied the way refundFees stores it, so the next
+			// one prorates what the earlier ones left.
+			transaction := Transaction{Amount: 100000, PlatformFee: 5000, ProcessingFee: 4000}
+
+			for i, amount := range test.refunds {
+				platformFee, processingFee := prorateFees(transaction, amount)
+				if platformFee != test.want[i][0] || processingFee != test.want[i][1] {
+					t.Errorf("refund %d of %d: fees = %d, %d, want %d, %d", i+1, amount, platformFee, processingFee, test.want[i][0], test.want[i][1])
+				}
+
+				if platformFee > transaction.PlatformFee || processingFee > transaction.ProcessingFee {
+					t.Errorf("refund %d of %d raised the fees from %d, %d to %d, %d", i+1, amount, transaction.PlatformFee, transaction.ProcessingFee, platformFee, processingFee)
+				}
+
+				transaction.RefundedAmount += amount
+				transaction.PlatformFee = platformFee
+				transaction.ProcessingFee = processingFee
+			}
+		})
+	}
+}
@@ -2,48 +2,1182 @@ package transaction
 
 import (
 	"cfa-backend/campaign"
+	"cfa-backend/fee"
+	"cfa-backend/guest"
+	"cfa-backend/payment"
+	"cfa-backend/user"
+	"context"
+	"crypto/rand"
 	"errors"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
 )
 
 type Service interface {
-	GetTransactionByID(input GetCampaignIDTransactionInput) ([]Transaction, error)
-	GetTransactionByUserID(userID int) ([]Transaction, error)
+	GetTransactionByID(input GetCampaignIDTransactionInput, listInput ListTransactionsInput) ([]Transaction, Page, error)
+	GetTransactionByUserID(userID int, listInput ListTransactionsInput) ([]Transaction, Page, error)
+	GetCampaignTotals(input GetCampaignIDTransactionInput) (CampaignTotals, error)
+	CreateTransaction(input CreateTransactionInput) (Transaction, error)
+	ProcessPaymentNotification(payload []byte) (Transaction, error)
+	GetTransactionHistory(input GetTransactionDetailInput) ([]TransactionStatusHistory, error)
+	ExpireStaleTransactions(ctx context.Context, ttl time.Duration) (int, error)
+	RefundTransaction(inputURI GetTransactionDetailInput, input CreateRefundInput) (Refund, error)
+	GetRefunds(input GetTransactionDetailInput) ([]Refund, error)
+	GetCampaignSupporters(input GetCampaignSupportersInput) ([]Transaction, error)
+	CreateGuestTransaction(input CreateGuestTransactionInput) (Transaction, error)
+	GetGuestTransaction(input GetGuestTransactionInput) (Transaction, error)
+	RequestGuestMerge(newUser user.User) error
+	ConfirmGuestMerge(input ConfirmGuestMergeInput) (int, error)
+	GetExportFilter(inputURI GetCampaignIDTransactionInput, input ExportCampaignTransactionsInput) (TransactionFilter, error)
+	ExportTransactions(filter TransactionFilter, fn func(Transaction) error) error
+	RefundCampaignTransactions(ctx context.Context, campaignID int, reason string) (int, error)
+	RetryRefunds(ctx context.Context) (int, error)
+	GetTransactionsForReview() ([]Transaction, error)
 }
 
+var (
+	ErrTransactionNotFound   = errors.New("No transaction found with that code")
+	ErrCampaignNotFound      = errors.New("No campaign found with that ID")
+	ErrBelowMinimumAmount    = errors.New("Donation amount is below the minimum amount!")
+	ErrNotAcceptingDonations = campaign.ErrNotAcceptingDonations
+	ErrRefundExceedsAmount   = errors.New("Refund amount exceeds the refundable amount!")
+	ErrRefundExceedsBalance  = errors.New("Refund amount exceeds the funds the campaign has left after payouts!")
+)
+
+// MinimumAmount is the smallest donation accepted for a campaign, in rupiah.
+const MinimumAmount = 10000
+
+// expiryBatchSize is how many stale transactions are resolved per worker run.
+const expiryBatchSize = 100
+
+// refundBatchSize is how many pending refunds are retried per worker run.
+const refundBatchSize = 100
+
+// maxRefundAttempts is how many times a refund is tried before it is left to
+// an operator. With the backoff below that is about a day and a half.
+const maxRefundAttempts = 10
+
+// expiryRetryDelay and refundRetryDelay are how long a stale transaction that
+// could not be resolved, and a refund whose outcome is unknown, are left
+// alone before the next try. The delay doubles with every failed try up to
+// maxRetryDelay.
+const (
+	expiryRetryDelay = 5 * time.Minute
+	refundRetryDelay = 5 * time.Minute
+	maxRetryDelay    = 6 * time.Hour
+)
+
+// exportBatchSize is how many transactions an export loads from the database
+// at a time.
+const exportBatchSize = 500
+
+const (
+	defaultPageLimit = 20
+	dateLayout       = "2006-01-02"
+)
+
+// supporterWallSize is how many donations the public supporter wall shows.
+const supporterWallSize = 50
+
+// codeAlphabet leaves out characters that are easy to misread (0/O, 1/I/L).
+const codeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
 type service struct {
 	repository         Repository
 	campaignRepository campaign.Repository
+	paymentGateway     payment.Gateway
+	feeSchedule        fee.Schedule
+	guestService       guest.Service
+	withdrawals        Withdrawals
+	paymentWindow      time.Duration
 }
 
-func NewService(repository Repository, campaignRepository campaign.Repository) *service {
-	return &service{repository: repository, campaignRepository: campaignRepository}
+// NewService creates the transaction service. paymentWindow is how long a
+// pending transaction may be paid; the gateway's payment page expires with it.
+func NewService(repository Repository, campaignRepository campaign.Repository, paymentGateway payment.Gateway, feeSchedule fee.Schedule, guestService guest.Service, withdrawals Withdrawals, paymentWindow time.Duration) *service {
+	return &service{
+		repository:         repository,
+		campaignRepository: campaignRepository,
+		paymentGateway:     paymentGateway,
+		feeSchedule:        feeSchedule,
+		guestService:       guestService,
+		withdrawals:        withdrawals,
+		paymentWindow:      paymentWindow,
+	}
 }
 
-func (s *service) GetTransactionByID(input GetCampaignIDTransactionInput) ([]Transaction, error) {
+func (s *service) GetTransactionByID(input GetCampaignIDTransactionInput, listInput ListTransactionsInput) ([]Transaction, Page, error) {
 	campaign, err := s.campaignRepository.FindByID(input.ID)
 
 	if err != nil {
-		return []Transaction{}, err
+		return []Transaction{}, Page{}, err
 	}
 
 	if campaign.UserID != input.User.ID {
-		return []Transaction{}, errors.New("You do not have authorization to get list of campaign transactions!")
+		return []Transaction{}, Page{}, errors.New("You do not have authorization to get list of campaign transactions!")
+	}
+
+	filter, pageQuery, err := parseListInput(listInput)
+	if err != nil {
+		return []Transaction{}, Page{}, err
+	}
+	filter.CampaignID = input.ID
+
+	transactions, err := s.repository.GetTransactionByCampaignID(filter, pageQuery)
+
+	if err != nil {
+		return transactions, Page{}, err
+	}
+
+	transactions, page := paginate(transactions, pageQuery)
+
+	return transactions, page, nil
+}
+
+// GetCampaignTotals sums up the paid donations of a campaign, net of refunds
+// and fees, for its owner.
+func (s *service) GetCampaignTotals(input GetCampaignIDTransactionInput) (CampaignTotals, error) {
+	campaign, err := s.campaignRepository.FindByID(input.ID)
+	if err != nil {
+		return CampaignTotals{}, err
+	}
+
+	if campaign.UserID != input.User.ID {
+		return CampaignTotals{}, errors.New("You do not have authorization to get list of campaign transactions!")
+	}
+
+	totals, err := s.repository.SumTotalsByCampaignID(campaign.ID)
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
+
+func (s *service) GetTransactionByUserID(userID int, listInput ListTransactionsInput) ([]Transaction, Page, error) {
+	filter, pageQuery, err := parseListInput(listInput)
+	if err != nil {
+		return []Transaction{}, Page{}, err
+	}
+	filter.UserID = userID
+
+	transactions, err := s.repository.GetTransactionByUserID(filter, pageQuery)
+	if err != nil {
+		return transactions, Page{}, err
+	}
+
+	transactions, page := paginate(transactions, pageQuery)
+
+	return transactions, page, nil
+}
+
+func (s *service) CreateTransaction(input CreateTransactionInput) (Transaction, error) {
+	campaign, err := s.campaignRepository.FindByID(input.CampaignID)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	if campaign.ID == 0 {
+		return Transaction{}, ErrCampaignNotFound
+	}
+
+	if !campaign.AcceptsDonations(time.Now()) {
+		return Transaction{}, ErrNotAcceptingDonations
+	}
+
+	if input.Amount < MinimumAmount {
+		return Transaction{}, ErrBelowMinimumAmount
+	}
+
+	if input.RewardTierID != 0 {
+		err = s.reserveRewardTier(campaign.ID, input)
+		if err != nil {
+			return Transaction{}, err
+		}
+	}
+
+	transaction := Transaction{
+		CampaignID:      campaign.ID,
+		UserID:          input.User.ID,
+		GuestID:         input.Guest.ID,
+		SubscriptionID:  input.SubscriptionID,
+		Amount:          input.Amount,
+		Status:          StatusPending,
+		IsAnonymous:     input.IsAnonymous,
+		HideAmount:      input.HideAmount,
+		Message:         strings.TrimSpace(input.Message),
+		RewardTierID:    input.RewardTierID,
+		ShippingAddress: strings.TrimSpace(input.ShippingAddress),
+	}
+	transaction = s.applyFees(transaction)
+
+	actor := ActorUser(input.User.ID)
+	customerName, customerEmail := input.User.Name, input.User.Email
+	if input.Guest.ID != 0 {
+		actor = ActorGuest(input.Guest.ID)
+		customerName, customerEmail = input.Guest.Name, input.Guest.Email
+	}
+
+	history := TransactionStatusHistory{
+		ToStatus: StatusPending,
+		Actor:    actor,
+		Reason:   "Donation created",
+	}
+
+	newTransaction, err := s.saveWithCode(transaction, history)
+	if err != nil {
+		s.releaseRewardTier(transaction)
+		return newTransaction, err
+	}
+
+	charge := payment.Charge{
+		OrderID:       newTransaction.Code,
+		Amount:        newTransaction.Amount,
+		ItemName:      campaign.Name,
+		CustomerName:  customerName,
+		CustomerEmail: customerEmail,
+		Expiry:        s.paymentWindow,
+	}
+
+	chargeResult, err := s.paymentGateway.CreateCharge(charge)
+	if err != nil {
+		s.transition(newTransaction, StatusFailed, ActorSystem, "Payment gateway rejected the charge: "+err.Error(), "")
+
+		return newTransaction, err
+	}
+
+	newTransaction.PaymentURL = chargeResult.RedirectURL
+	newTransaction.PaymentToken = chargeResult.Token
+
+	updatedTransaction, err := s.repository.Update(newTransaction)
+	if err != nil {
+		return updatedTransaction, err
+	}
+
+	return updatedTransaction, nil
+}
+
+// GetExportFilter checks that the user may export the campaign's transactions,
+// the same way GetTransactionByID does, and turns the export input into a
+// filter for ExportTransactions.
+func (s *service) GetExportFilter(inputURI GetCampaignIDTransactionInput, input ExportCampaignTransactionsInput) (TransactionFilter, error) {
+	campaign, err := s.campaignRepository.FindByID(inputURI.ID)
+	if err != nil {
+		return TransactionFilter{}, err
+	}
+
+	if campaign.ID == 0 || campaign.UserID != input.User.ID {
+		return TransactionFilter{}, errors.New("You do not have authorization to export the campaign transactions!")
+	}
+
+	filter := TransactionFilter{CampaignID: campaign.ID, Status: input.Status}
+
+	filter.From, filter.To, err = parseDateRange(input.From, input.To)
+	if err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// ExportTransactions streams the transactions matching a filter obtained from
+// GetExportFilter to fn.
+func (s *service) ExportTransactions(filter TransactionFilter, fn func(Transaction) error) error {
+	return s.repository.EachByFilter(filter, exportBatchSize, fn)
+}
+
+// CreateGuestTransaction creates a donation for a backer without an account and
+// emails them a magic link to its receipt. A failure to send the email does not
+// fail the donation, the payment page is returned to the guest either way.
+func (s *service) CreateGuestTransaction(input CreateGuestTransactionInput) (Transaction, error) {
+	guest, err := s.guestService.FindOrCreateGuest(strings.TrimSpace(input.Name), input.Email)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	newTransaction, err := s.CreateTransaction(CreateTransactionInput{
+		Amount:          input.Amount,
+		CampaignID:      input.CampaignID,
+		IsAnonymous:     input.IsAnonymous,
+		HideAmount:      input.HideAmount,
+		Message:         input.Message,
+		RewardTierID:    input.RewardTierID,
+		ShippingAddress: input.ShippingAddress,
+		Guest:           guest,
+	})
+	if err != nil {
+		return newTransaction, err
+	}
+
+	err = s.guestService.SendReceiptLink(guest, newTransaction.Code, newTransaction.PaymentURL)
+	if err != nil {
+		log.Printf("failed to send receipt link of transaction %s: %v", newTransaction.Code, err)
+	}
+
+	newTransaction.Guest = guest
+
+	return newTransaction, nil
+}
+
+// GetGuestTransaction returns the transaction a guest receipt link points at,
+// after checking the link's token.
+func (s *service) GetGuestTransaction(input GetGuestTransactionInput) (Transaction, error) {
+	err := s.guestService.ValidateReceiptToken(input.Code, input.Token)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	transaction, err := s.repository.FindByCode(input.Code)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.ID == 0 {
+		return transaction, ErrTransactionNotFound
+	}
+
+	campaign, err := s.campaignRepository.FindByID(transaction.CampaignID)
+	if err != nil {
+		return transaction, err
 	}
 
-	transactions, err := s.repository.GetTransactionByCampaignID(input.ID)
+	transaction.Campaign = campaign
+
+	return transaction, nil
+}
 
+// RequestGuestMerge emails a newly registered user who donated as a guest
+// with the same email before a link to move those donations to the account.
+// They are only moved once the link proves that the email is the user's.
+func (s *service) RequestGuestMerge(newUser user.User) error {
+	guest, err := s.guestService.GetGuestByEmail(newUser.Email)
+	if err != nil {
+		return err
+	}
+
+	if guest.ID == 0 || guest.UserID != 0 {
+		return nil
+	}
+
+	return s.guestService.SendMergeLink(guest, newUser)
+}
+
+// ConfirmGuestMerge moves the donations made as a guest over to the user the
+// merge link was sent to. It returns how many transactions were moved, which
+// is none when the link has been used before.
+func (s *service) ConfirmGuestMerge(input ConfirmGuestMergeInput) (int, error) {
+	guest, verifiedUser, err := s.guestService.VerifyMergeToken(input.Token)
+	if err != nil {
+		return 0, err
+	}
+
+	if guest.UserID != 0 {
+		return 0, nil
+	}
+
+	merged, err := s.repository.AssignGuestTransactions(guest.ID, verifiedUser.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = s.guestService.MarkAsMerged(guest, verifiedUser.ID)
+	if err != nil {
+		return merged, err
+	}
+
+	return merged, nil
+}
+
+// ProcessPaymentNotification verifies a gateway notification and applies the
+// payment status it carries to the matching transaction. Notifications are
+// retried by the gateway, so applying the same one twice is a no-op, and
+// notifications that do not fit the state machine are ignored. The exception
+// is money received for a transaction that has already expired or failed,
+// which is flagged for an operator to refund instead.
+func (s *service) ProcessPaymentNotification(payload []byte) (Transaction, error) {
+	paymentStatus, err := s.paymentGateway.ParseNotification(payload)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	transaction, err := s.repository.FindByCode(paymentStatus.OrderID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.ID == 0 {
+		return transaction, ErrTransactionNotFound
+	}
+
+	if paymentStatus.Amount != transaction.Amount {
+		return transaction, errors.New("Notification amount does not match the transaction amount!")
+	}
+
+	// Refunds of ours that the notification is about are recorded as such
+	// first, so that only what they do not cover counts as refunded at the
+	// gateway directly.
+	err = s.reconcileRefunds(transaction)
+	if err != nil {
+		return transaction, err
+	}
+
+	transaction, err = s.repository.FindByCode(paymentStatus.OrderID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if paymentStatus.PaymentMethod != "" {
+		transaction.PaymentMethod = paymentStatus.PaymentMethod
+	}
+
+	if isLatePayment(transaction, paymentStatus) {
+		return s.flagLatePayment(transaction, paymentStatus, string(payload))
+	}
+
+	// A status the transaction has already reached, or cannot reach, leaves
+	// it as it is.
+	path, _ := transitionPath(transaction.Status, paymentStatus.Status)
+
+	reason := "Gateway reported " + paymentStatus.GatewayStatus
+	for _, status := range path {
+		transaction, err = s.transition(transaction, status, ActorGateway, reason, string(payload))
+		if err != nil {
+			return transaction, err
+		}
+	}
+
+	// Every valid notification posts whatever the ledger still misses, so a
+	// retried notification repairs a transaction whose status got ahead of
+	// its ledger.
+	err = s.repository.PostToLedger(transaction)
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// isLatePayment reports whether the gateway took money for a transaction that
+// can no longer be paid, e.g. because the backer paid on a payment page that
+// was left open after the transaction expired.
+func isLatePayment(transaction Transaction, paymentStatus payment.StatusResult) bool {
+	if transaction.Status != StatusExpired && transaction.Status != StatusFailed {
+		return false
+	}
+
+	return paymentStatus.Status == payment.StatusPaid || paymentStatus.Status == payment.StatusSettled
+}
+
+// flagLatePayment keeps the transaction's status, so the money is not counted
+// towards the campaign, and flags it for an operator to refund.
+func (s *service) flagLatePayment(transaction Transaction, paymentStatus payment.StatusResult, payload string) (Transaction, error) {
+	history := TransactionStatusHistory{
+		FromStatus: transaction.Status,
+		ToStatus:   transaction.Status,
+		Actor:      ActorGateway,
+		Reason:     "Gateway reported " + paymentStatus.GatewayStatus + " after the transaction was " + transaction.Status + "; the payment has to be refunded",
+		Payload:    payload,
+	}
+
+	flagged, err := s.repository.FlagForReview(transaction, history)
+	if err != nil {
+		return transaction, err
+	}
+
+	if flagged {
+		log.Printf("transaction %s was paid after it was %s and is flagged for review", transaction.Code, transaction.Status)
+
+		now := time.Now()
+		transaction.ReviewReason = history.Reason
+		transaction.FlaggedAt = &now
+	}
+
+	return transaction, nil
+}
+
+// GetTransactionsForReview lists the transactions flagged for an operator.
+func (s *service) GetTransactionsForReview() ([]Transaction, error) {
+	transactions, err := s.repository.FindFlaggedForReview()
 	if err != nil {
 		return transactions, err
 	}
 
-	return transactions, err
+	return transactions, nil
+}
+
+func (s *service) GetTransactionHistory(input GetTransactionDetailInput) ([]TransactionStatusHistory, error) {
+	transaction, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return []TransactionStatusHistory{}, err
+	}
+
+	if transaction.ID == 0 {
+		return []TransactionStatusHistory{}, errors.New("No transaction found with that ID")
+	}
+
+	if !canManage(transaction, input.User) {
+		return []TransactionStatusHistory{}, errors.New("You do not have authorization to get the transaction history!")
+	}
+
+	histories, err := s.repository.GetStatusHistories(transaction.ID)
+	if err != nil {
+		return histories, err
+	}
+
+	return histories, nil
+}
+
+// ExpireStaleTransactions resolves transactions that have been pending for
+// longer than ttl. The gateway is asked for the final status first so that a
+// payment whose notification got lost is marked paid rather than expired; a
+// payment that is still pending at the gateway is cancelled there and expired.
+// Transactions that fail to resolve, e.g. because the gateway errors, are
+// retried with a growing delay so they do not hold up the ones behind them.
+// It returns how many transactions were resolved.
+func (s *service) ExpireStaleTransactions(ctx context.Context, ttl time.Duration) (int, error) {
+	now := time.Now()
+	transactions, err := s.repository.FindPendingCreatedBefore(now.Add(-ttl), now, expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for _, transaction := range transactions {
+		if ctx.Err() != nil {
+			return resolved, ctx.Err()
+		}
+
+		err := s.resolveStaleTransaction(transaction)
+		if err != nil {
+			log.Printf("failed to resolve stale transaction %s: %v", transaction.Code, err)
+
+			err = s.repository.DeferExpiry(transaction, now.Add(backoff(expiryRetryDelay, transaction.ExpiryAttempts)))
+			if err != nil {
+				return resolved, err
+			}
+			continue
+		}
+
+		resolved++
+	}
+
+	return resolved, nil
+}
+
+// backoff returns how long to wait before trying again after attempts failed
+// tries, starting at delay.
+func backoff(delay time.Duration, attempts int) time.Duration {
+	for i := 0; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+func (s *service) resolveStaleTransaction(transaction Transaction) error {
+	reason := "Pending for longer than the allowed time"
+
+	paymentStatus, err := s.paymentGateway.GetStatus(transaction.Code)
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		_, err = s.transition(transaction, StatusExpired, ActorSystem, reason, "")
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if paymentStatus.Status == payment.StatusPending {
+		_, err = s.paymentGateway.Cancel(transaction.Code)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.transition(transaction, StatusExpired, ActorSystem, reason, "")
+		return err
+	}
+
+	if paymentStatus.PaymentMethod != "" {
+		transaction.PaymentMethod = paymentStatus.PaymentMethod
+	}
+
+	path, err := transitionPath(transaction.Status, paymentStatus.Status)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range path {
+		transaction, err = s.transition(transaction, status, ActorSystem, "Gateway reported "+paymentStatus.GatewayStatus, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefundTransaction refunds a paid transaction fully or partially through the
+// payment gateway. Only the campaign owner and admins may refund; an amount
+// of zero refunds everything that has not been refunded yet.
+func (s *service) RefundTransaction(inputURI GetTransactionDetailInput, input CreateRefundInput) (Refund, error) {
+	transaction, err := s.repository.FindByID(inputURI.ID)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	if transaction.ID == 0 {
+		return Refund{}, errors.New("No transaction found with that ID")
+	}
+
+	if !canManage(transaction, input.User) {
+		return Refund{}, errors.New("You do not have authorization to refund the transaction!")
+	}
+
+	if transaction.Status != StatusPaid && transaction.Status != StatusSettled {
+		return Refund{}, errors.New("Only paid transactions can be refunded!")
+	}
+
+	refundable := transaction.Amount - transaction.RefundedAmount
+	amount := input.Amount
+	if amount == 0 {
+		amount = refundable
+	}
+
+	if amount < 0 || amount > refundable {
+		return Refund{}, ErrRefundExceedsAmount
+	}
+
+	return s.refund(transaction, amount, input.Reason, input.User.ID)
+}
+
+// RefundCampaignTransactions fully refunds every paid donation of a campaign,
+// e.g. when an all-or-nothing campaign missed its goal. It returns how many
+// donations are left to refund: those whose refund failed or is still being
+// processed and those still pending payment, which may be paid later and
+// must be refunded then. A donation with an open refund is left to that
+// refund, which RetryRefunds tries again under its own key, and a donation
+// the gateway refused to refund maxRefundAttempts times is left to an
+// operator.
+func (s *service) RefundCampaignTransactions(ctx context.Context, campaignID int, reason string) (int, error) {
+	transactions, err := s.repository.GetTransactionByCampaignID(TransactionFilter{CampaignID: campaignID}, PageQuery{Sort: SortOldest})
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := 0
+	for _, transaction := range transactions {
+		if ctx.Err() != nil {
+			return remaining, ctx.Err()
+		}
+
+		switch transaction.Status {
+		case StatusPending:
+			remaining++
+		case StatusPaid, StatusSettled:
+			refunds, err := s.repository.GetRefunds(transaction.ID)
+			if err != nil {
+				return remaining, err
+			}
+
+			open, failed := 0, 0
+			for _, refund := range refunds {
+				if slices.Contains(openRefundStatuses, refund.Status) {
+					open++
+				} else if refund.Status == RefundStatusFailed {
+					failed++
+				}
+			}
+
+			if open > 0 || failed >= maxRefundAttempts {
+				remaining++
+				continue
+			}
+
+			refund, err := s.refund(transaction, transaction.Amount-transaction.RefundedAmount, reason, 0)
+			if err != nil {
+				log.Printf("failed to refund transaction %s: %v", transaction.Code, err)
+			}
+
+			if err != nil || refund.Status != RefundStatusSucceeded {
+				remaining++
+			}
+		}
+	}
+
+	return remaining, nil
+}
+
+// RetryRefunds tries the pending refunds whose outcome was unknown again. The
+// gateway is asked first whether it made the refund already, e.g. when the
+// refund went through but could not be recorded, and is only sent the
+// refund again, under the same key, when it did not. It returns how many
+// refunds were completed.
+func (s *service) RetryRefunds(ctx context.Context) (int, error) {
+	refunds, err := s.repository.FindRefundsDue(time.Now(), refundBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, refund := range refunds {
+		if ctx.Err() != nil {
+			return completed, ctx.Err()
+		}
+
+		transaction, err := s.repository.FindByID(refund.TransactionID)
+		if err != nil {
+			return completed, err
+		}
+
+		paymentStatus, err := s.paymentGateway.GetStatus(transaction.Code)
+		if err != nil {
+			_, err = s.deferRefund(refund, err)
+			if err != nil {
+				return completed, err
+			}
+			continue
+		}
+
+		if gatewayRefunded(paymentStatus, refund) {
+			refund, err = s.applyRefund(transaction, refund)
+		} else {
+			refund, err = s.sendRefund(transaction, refund)
+		}
+
+		if err != nil {
+			log.Printf("failed to retry refund %s: %v", refund.RefundKey, err)
+			continue
+		}
+
+		if refund.Status == RefundStatusSucceeded {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// refund records the intent to refund amount of a transaction and then
+// carries it out through the payment gateway. The refund is returned pending
+// when its outcome is not known yet; RetryRefunds or the gateway's
+// notification finishes it later.
+func (s *service) refund(transaction Transaction, amount int, reason string, requestedBy int) (Refund, error) {
+	// Should this process die before the refund is settled one way or the
+	// other, RetryRefunds picks it up from here.
+	nextAttempt := time.Now().Add(refundRetryDelay)
+
+	suffix, err := randomCode(6)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	refund, err := s.repository.SaveRefundWithinBalance(Refund{
+		TransactionID: transaction.ID,
+		Amount:        amount,
+		Reason:        reason,
+		Status:        RefundStatusPending,
+		RefundKey:     transaction.Code + "-R" + suffix,
+		RequestedBy:   requestedBy,
+		NextAttemptAt: &nextAttempt,
+	}, s.withdrawals)
+	if err != nil {
+		return refund, err
+	}
+
+	return s.sendRefund(transaction, refund)
+}
+
+// sendRefund asks the gateway for a pending refund and applies it once the
+// gateway made it. A refusal fails the refund; any other error leaves it
+// unknown whether the money left, so the refund stays pending to be retried.
+func (s *service) sendRefund(transaction Transaction, refund Refund) (Refund, error) {
+	_, err := s.paymentGateway.Refund(transaction.Code, payment.RefundRequest{
+		RefundKey: refund.RefundKey,
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+	})
+
+	if errors.Is(err, payment.ErrRefundRejected) || errors.Is(err, payment.ErrPaymentNotFound) {
+		refund.Status = RefundStatusFailed
+		refund.FailureReason = err.Error()
+
+		failErr := s.repository.FailRefund(refund)
+		if failErr != nil {
+			return refund, failErr
+		}
+
+		return refund, err
+	}
+
+	if err != nil {
+		return s.deferRefund(refund, err)
+	}
+
+	return s.applyRefund(transaction, refund)
+}
+
+// applyRefund records a refund the gateway has made. When that fails the
+// refund stays pending, and since the gateway lists it by then, the retry
+// only records it.
+func (s *service) applyRefund(transaction Transaction, refund Refund) (Refund, error) {
+	actor := ActorSystem
+	if refund.RequestedBy != 0 {
+		actor = ActorUser(refund.RequestedBy)
+	}
+
+	history := TransactionStatusHistory{
+		Actor:  actor,
+		Reason: "Refunded: " + refund.Reason,
+	}
+
+	appliedRefund, completed, err := s.repository.ApplyRefund(refund, history)
+	if err != nil {
+		return s.deferRefund(refund, err)
+	}
+
+	if completed {
+		s.releaseRewardTier(transaction)
+	}
+
+	return appliedRefund, nil
+}
+
+// deferRefund leaves a refund whose outcome is unknown pending and schedules
+// its next try, or sets it aside for an operator once it has been tried
+// maxRefundAttempts times.
+func (s *service) deferRefund(refund Refund, cause error) (Refund, error) {
+	refund.FailureReason = cause.Error()
+
+	if refund.Attempts+1 >= maxRefundAttempts {
+		log.Printf("refund %s needs review after %d tries: %v", refund.RefundKey, refund.Attempts+1, cause)
+
+		err := s.repository.EscalateRefund(refund)
+		if err != nil {
+			return refund, err
+		}
+		refund.Attempts++
+		refund.Status = RefundStatusNeedsReview
+
+		return refund, nil
+	}
+
+	log.Printf("refund %s is still pending: %v", refund.RefundKey, cause)
+
+	err := s.repository.DeferRefund(refund, time.Now().Add(backoff(refundRetryDelay, refund.Attempts)))
+	if err != nil {
+		return refund, err
+	}
+	refund.Attempts++
+
+	return refund, nil
+}
+
+// reconcileRefunds applies the open refunds of a transaction that the
+// gateway reports as made, e.g. when a refund notification arrives before the
+// refund could be recorded or after it was set aside for review.
+func (s *service) reconcileRefunds(transaction Transaction) error {
+	refunds, err := s.repository.GetRefunds(transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	var pending []Refund
+	for _, refund := range refunds {
+		if slices.Contains(openRefundStatuses, refund.Status) {
+			pending = append(pending, refund)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	paymentStatus, err := s.paymentGateway.GetStatus(transaction.Code)
+	if err != nil {
+		return err
+	}
+
+	for _, refund := range pending {
+		if !gatewayRefunded(paymentStatus, refund) {
+			continue
+		}
+
+		_, err = s.applyRefund(transaction, refund)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (s *service) GetTransactionByUserID(userID int) ([]Transaction, error) {
-	transactions, err := s.repository.GetTransactionByUserID(userID)
+// gatewayRefunded reports whether the gateway lists the refund as made.
+func gatewayRefunded(paymentStatus payment.StatusResult, refund Refund) bool {
+	for _, gatewayRefund := range paymentStatus.Refunds {
+		if gatewayRefund.RefundKey == refund.RefundKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *service) GetRefunds(input GetTransactionDetailInput) ([]Refund, error) {
+	transaction, err := s.repository.FindByID(input.ID)
+	if err != nil {
+		return []Refund{}, err
+	}
+
+	if transaction.ID == 0 {
+		return []Refund{}, errors.New("No transaction found with that ID")
+	}
+
+	if !canManage(transaction, input.User) {
+		return []Refund{}, errors.New("You do not have authorization to get the transaction refunds!")
+	}
+
+	refunds, err := s.repository.GetRefunds(transaction.ID)
+	if err != nil {
+		return refunds, err
+	}
+
+	return refunds, nil
+}
+
+// GetCampaignSupporters returns the most recent paid donations of a campaign
+// for its public supporter wall. Like the campaign itself, the wall of a
+// campaign that is not public yet is only shown to its owner and admins.
+func (s *service) GetCampaignSupporters(input GetCampaignSupportersInput) ([]Transaction, error) {
+	campaign, err := s.campaignRepository.FindByID(input.ID)
+	if err != nil {
+		return []Transaction{}, err
+	}
+
+	if campaign.ID == 0 || !campaign.VisibleTo(input.User) {
+		return []Transaction{}, ErrCampaignNotFound
+	}
+
+	transactions, err := s.repository.GetSupportersByCampaignID(input.ID, supporterWallSize)
 	if err != nil {
 		return transactions, err
 	}
 
-	return transactions, err
+	return transactions, nil
+}
+
+// applyFees stores the fee breakdown of the fee schedule on the transaction.
+func (s *service) applyFees(transaction Transaction) Transaction {
+	breakdown := s.feeSchedule.Calculate(transaction.Amount, transaction.PaymentMethod, transaction.CampaignID)
+
+	transaction.PlatformFee = breakdown.PlatformFee
+	transaction.ProcessingFee = breakdown.ProcessingFee
+	transaction.NetAmount = breakdown.Net
+
+	return transaction
+}
+
+// canManage reports whether the user may manage the money of a transaction,
+// which is the case for the owner of its campaign and for admins.
+func canManage(transaction Transaction, currentUser user.User) bool {
+	return transaction.Campaign.UserID == currentUser.ID || currentUser.Role == user.RoleAdmin
+}
+
+// transition moves a transaction to status, enforcing the state machine and
+// recording who made the change, why, and the raw gateway payload if any. A
+// transition lost to a concurrent update leaves the transaction untouched.
+func (s *service) transition(transaction Transaction, status string, actor string, reason string, payload string) (Transaction, error) {
+	if !CanTransition(transaction.Status, status) {
+		return transaction, ErrInvalidTransition
+	}
+
+	// Fees depend on the payment method, which is only known once paid.
+	if status == StatusPaid {
+		transaction = s.applyFees(transaction)
+	}
+
+	history := TransactionStatusHistory{
+		TransactionID: transaction.ID,
+		FromStatus:    transaction.Status,
+		ToStatus:      status,
+		Actor:         actor,
+		Reason:        reason,
+		Payload:       payload,
+	}
+
+	updated, err := s.repository.Transition(transaction, history)
+	if err != nil {
+		return transaction, err
+	}
+
+	if updated {
+		transaction.Status = status
+
+		// A donation that is not going to be paid, or was paid back, no
+		// longer holds its reward.
+		if status == StatusFailed || status == StatusExpired || status == StatusRefunded {
+			s.releaseRewardTier(transaction)
+		}
+	}
+
+	return transaction, nil
+}
+
+// reserveRewardTier checks that the selected reward tier belongs to the
+// campaign and that the donation qualifies for it, then reserves one of its
+// rewards.
+func (s *service) reserveRewardTier(campaignID int, input CreateTransactionInput) error {
+	rewardTier, err := s.campaignRepository.FindRewardTierByID(input.RewardTierID)
+	if err != nil {
+		return err
+	}
+
+	if rewardTier.ID == 0 || rewardTier.CampaignID != campaignID {
+		return errors.New("No reward tier found with that ID")
+	}
+
+	if input.Amount < rewardTier.MinimumPledge {
+		return errors.New("Donation amount is below the minimum pledge of the reward tier!")
+	}
+
+	if rewardTier.ShippingRequired && strings.TrimSpace(input.ShippingAddress) == "" {
+		return errors.New("The selected reward tier requires a shipping address!")
+	}
+
+	return s.campaignRepository.ReserveRewardTier(rewardTier.ID)
+}
+
+func (s *service) releaseRewardTier(transaction Transaction) {
+	if transaction.RewardTierID == 0 {
+		return
+	}
+
+	err := s.campaignRepository.ReleaseRewardTier(transaction.RewardTierID)
+	if err != nil {
+		log.Printf("failed to release reward tier %d of transaction %d: %v", transaction.RewardTierID, transaction.ID, err)
+	}
+}
+
+// saveWithCode stores a new transaction and its first history row under a
+// freshly generated code. The unique index on transactions.code rejects a code
+// that is already taken, in which case another one is tried.
+func (s *service) saveWithCode(transaction Transaction, history TransactionStatusHistory) (Transaction, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generateCode()
+		if err != nil {
+			return transaction, err
+		}
+		transaction.Code = code
+
+		newTransaction, err := s.repository.Save(transaction, history)
+		if errors.Is(err, ErrDuplicateCode) {
+			continue
+		}
+
+		return newTransaction, err
+	}
+
+	return transaction, errors.New("Failed to generate a unique transaction code!")
+}
+
+// generateCode returns a random code such as CFA-20250124-7K3QX9.
+func generateCode() (string, error) {
+	suffix, err := randomCode(6)
+	if err != nil {
+		return "", err
+	}
+
+	return "CFA-" + time.Now().Format("20060102") + "-" + suffix, nil
+}
+
+// randomCode returns length random characters of codeAlphabet.
+func randomCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// parseListInput turns the query of a transaction list into a filter and the
+// page to return.
+func parseListInput(input ListTransactionsInput) (TransactionFilter, PageQuery, error) {
+	filter := TransactionFilter{Status: input.Status, MinAmount: input.MinAmount, MaxAmount: input.MaxAmount}
+	page := PageQuery{Sort: input.Sort, Limit: input.Limit}
+
+	if page.Sort == "" {
+		page.Sort = SortNewest
+	}
+
+	if page.Limit == 0 {
+		page.Limit = defaultPageLimit
+	}
+
+	if filter.MaxAmount != 0 && filter.MinAmount > filter.MaxAmount {
+		return filter, page, errors.New("The minimum amount must not be above the maximum amount!")
+	}
+
+	var err error
+	filter.From, filter.To, err = parseDateRange(input.From, input.To)
+	if err != nil {
+		return filter, page, err
+	}
+
+	if input.Cursor != "" {
+		page.Cursor, err = decodeCursor(page.Sort, input.Cursor)
+		if err != nil {
+			return filter, page, err
+		}
+	}
+
+	return filter, page, nil
+}
+
+// paginate cuts the extra transaction the repository loaded to look ahead off
+// the page and points the next cursor at the last transaction kept.
+func paginate(transactions []Transaction, query PageQuery) ([]Transaction, Page) {
+	page := Page{Limit: query.Limit}
+
+	if query.Limit > 0 && len(transactions) > query.Limit {
+		transactions = transactions[:query.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(query.Sort, transactions[len(transactions)-1])
+	}
+
+	return transactions, page
+}
+
+// parseDateRange parses a range of whole days in local time. Both days are
+// included, so the returned end is the start of the day after to.
+func parseDateRange(from string, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+
+	if from != "" {
+		start, err = time.ParseInLocation(dateLayout, from, time.Local)
+		if err != nil {
+			return start, end, err
+		}
+	}
+
+	if to != "" {
+		end, err = time.ParseInLocation(dateLayout, to, time.Local)
+		if err != nil {
+			return start, end, err
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return start, end, errors.New("The from date must not be after the to date!")
+	}
+
+	return start, end, nil
 }
@@ -0,0 +1,57 @@
+package transaction
+
+import (
+	"errors"
+	"strconv"
+)
+
+var ErrInvalidTransition = errors.New("Transaction status transition is not allowed!")
+
+const (
+	ActorGateway = "gateway"
+	ActorSystem  = "system"
+)
+
+// transitions lists, for every status, the statuses a transaction may move to
+// next. Statuses without an entry are final. Settled funds can still be
+// refunded, so settled → refunded is allowed alongside paid → refunded.
+var transitions = map[string][]string{
+	StatusPending: {StatusPaid, StatusExpired, StatusFailed},
+	StatusPaid:    {StatusSettled, StatusRefunded},
+	StatusSettled: {StatusRefunded},
+}
+
+func CanTransition(from string, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// transitionPath returns the statuses a transaction has to go through to get
+// from one status to another, e.g. a gateway may report a pending payment as
+// settled straight away, which is recorded as pending → paid → settled.
+func transitionPath(from string, to string) ([]string, error) {
+	if CanTransition(from, to) {
+		return []string{to}, nil
+	}
+
+	for _, next := range transitions[from] {
+		if CanTransition(next, to) {
+			return []string{next, to}, nil
+		}
+	}
+
+	return nil, ErrInvalidTransition
+}
+
+func ActorUser(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+func ActorGuest(guestID int) string {
+	return "guest:" + strconv.Itoa(guestID)
+}
@@ -0,0 +1,87 @@
+package transaction
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var statuses = []string{StatusPending, StatusPaid, StatusSettled, StatusFailed, StatusExpired, StatusRefunded}
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{StatusPending, StatusPaid}:     true,
+		{StatusPending, StatusExpired}:  true,
+		{StatusPending, StatusFailed}:   true,
+		{StatusPaid, StatusSettled}:     true,
+		{StatusPaid, StatusRefunded}:    true,
+		{StatusSettled, StatusRefunded}: true,
+	}
+
+	// Every pair of statuses not listed above is rejected, including staying
+	// in the same status and leaving a final status.
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]string{from, to}]
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%q, %q) = %t, want %t", from, to, got, want)
+			}
+		}
+	}
+
+	if CanTransition("", StatusPaid) || CanTransition(StatusPending, "unknown") {
+		t.Errorf("CanTransition allows a status it does not know")
+	}
+}
+
+func TestTransitionPath(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want []string
+	}{
+		{StatusPending, StatusPaid, []string{StatusPaid}},
+		{StatusPending, StatusSettled, []string{StatusPaid, StatusSettled}},
+		{StatusPending, StatusRefunded, []string{StatusPaid, StatusRefunded}},
+		{StatusPending, StatusExpired, []string{StatusExpired}},
+		{StatusPaid, StatusSettled, []string{StatusSettled}},
+		{StatusPaid, StatusRefunded, []string{StatusRefunded}},
+		{StatusSettled, StatusRefunded, []string{StatusRefunded}},
+	}
+
+	for _, test := range tests {
+		got, err := transitionPath(test.from, test.to)
+		if err != nil {
+			t.Errorf("transitionPath(%q, %q) error = %v", test.from, test.to, err)
+			continue
+		}
+
+		if !slices.Equal(got, test.want) {
+			t.Errorf("transitionPath(%q, %q) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestTransitionPathRejected(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+	}{
+		{StatusPending, StatusPending},
+		{StatusPaid, StatusPaid},
+		{StatusPaid, StatusPending},
+		{StatusSettled, StatusPaid},
+		{StatusExpired, StatusPaid},
+		{StatusExpired, StatusSettled},
+		{StatusFailed, StatusSettled},
+		{StatusRefunded, StatusSettled},
+		{StatusExpired, StatusRefunded},
+	}
+
+	for _, test := range tests {
+		got, err := transitionPath(test.from, test.to)
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("transitionPath(%q, %q) = %v, %v, want %v", test.from, test.to, got, err, ErrInvalidTransition)
+		}
+	}
+}
@@ -2,15 +2,18 @@ package user
 
 import "time"
 
+const RoleAdmin = "admin"
+
 type User struct {
-	ID             int
-	Name           string
-	Occupation     string
-	Email          string
-	PasswordHash   string
-	AvatarFileName string
-	Role           string
-	Token          string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID              int
+	Name            string
+	Occupation      string
+	Email           string
+	PasswordHash    string
+	AvatarFileName  string
+	Role            string
+	Token           string
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
@@ -1,12 +1,17 @@
 package user
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Repository interface {
 	Save(user User) (User, error)
 	FindByEmail(email string) (User, error)
 	FindByID(ID int) (User, error)
 	Update(user User) (User, error)
+	MarkEmailVerified(ID int, verifiedAt time.Time) error
 }
 
 type repository struct {
@@ -56,3 +61,9 @@ func (r *repository) Update(user User) (User, error) {
 
 	return user, nil
 }
+
+// MarkEmailVerified records when a user proved they own their email. A user
+// who has done so before keeps the first time.
+func (r *repository) MarkEmailVerified(ID int, verifiedAt time.Time) error {
+	return r.db.Model(&User{}).Where("id = ? AND email_verified_at IS NULL", ID).Update("email_verified_at", verifiedAt).Error
+}
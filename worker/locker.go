@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// Locker hands out named locks shared by every running instance, so a job is
+// only ever executed by one of them at a time.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
+
+type mysqlLocker struct {
+	db *gorm.DB
+}
+
+// NewMySQLLocker creates a Locker backed by MySQL GET_LOCK advisory locks.
+func NewMySQLLocker(db *gorm.DB) *mysqlLocker {
+	return &mysqlLocker{db}
+}
+
+// TryLock attempts to take the lock without waiting. MySQL named locks belong
+// to a session, so the lock is taken and released on one dedicated
+// connection that is held for as long as the lock is.
+func (l *mysqlLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&acquired)
+	if err != nil || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, false, err
+	}
+
+	release := func() {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		conn.Close()
+	}
+
+	return release, true, nil
+}
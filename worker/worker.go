@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a unit of background work. It should return promptly once ctx is
+// cancelled.
+type Job func(ctx context.Context) error
+
+// Run executes job every interval until ctx is cancelled. Each run first takes
+// the named lock, so when several instances are running only one of them
+// processes a given tick; the others simply skip it.
+func Run(ctx context.Context, name string, interval time.Duration, locker Locker, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runOnce(ctx, name, locker, job)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("worker %s: stopped", name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runOnce(ctx context.Context, name string, locker Locker, job Job) {
+	release, acquired, err := locker.TryLock(ctx, "cfa-worker:"+name)
+	if err != nil {
+		log.Printf("worker %s: failed to acquire lock: %v", name, err)
+		return
+	}
+
+	if !acquired {
+		return
+	}
+	defer release()
+
+	err = job(ctx)
+	if err != nil {
+		log.Printf("worker %s: %v", name, err)
+	}
+}